@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"tada/internal/todo"
+
+	"github.com/spf13/cobra"
+)
+
+var addDate bool
+
+var addCmd = &cobra.Command{
+	Use:   "add <text>",
+	Short: "Add a task without opening the TUI",
+	Long: `Add a task to todo.txt and print its line number.
+
+The text is parsed as a todo.txt line, so priorities, contexts, projects
+and tags work as usual. Multiple arguments are joined with spaces.
+
+Example:
+  tada add "(A) Call dentist @Personal +Health due:2025-10-01"`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		text := strings.TrimSpace(strings.Join(args, " "))
+		if text == "" {
+			fmt.Println("Error: task text is empty")
+			os.Exit(1)
+		}
+
+		todoFile := mustTodoFile()
+
+		item := newTask(text, addDate || mustLoadConfig().DateOnAdd, time.Now())
+
+		var lineNumber int
+		err := todo.Update(todoFile, todo.OpAdd, func(todos []todo.Item) ([]todo.Item, error) {
+			todos = append(todos, item)
+			lineNumber = len(todos)
+			return todos, nil
+		})
+		if err != nil && !warnJournal(err) {
+			fmt.Println("Error saving todo.txt:", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("%d %s\n", lineNumber, item.String())
+	},
+}
+
+// newTask parses the text of a new task, stamping it with now as creation
+// date if stamp is set and the text has no date yet
+func newTask(text string, stamp bool, now time.Time) todo.Item {
+	item := todo.Parse(text)
+	if stamp && item.CreationDate == "" && !item.Completed {
+		item.CreationDate = now.Format("2006-01-02")
+		item = item.Normalize()
+	}
+	return item
+}
+
+func init() {
+	rootCmd.AddCommand(addCmd)
+	addCmd.Flags().BoolVarP(&addDate, "date", "t", false, "Prefix the task with today's date as creation date, always on with the date_on_add setting")
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewTask(t *testing.T) {
+	now := time.Date(2025, 10, 15, 9, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name  string
+		text  string
+		stamp bool
+		want  string
+	}{
+		{name: "plain", text: "Call dentist @Personal", want: "Call dentist @Personal"},
+		{name: "stamped", text: "Call dentist", stamp: true, want: "2025-10-15 Call dentist"},
+		{name: "stamped after priority", text: "(A) Call dentist", stamp: true, want: "(A) 2025-10-15 Call dentist"},
+		{name: "own date kept", text: "2025-01-01 Call dentist", stamp: true, want: "2025-01-01 Call dentist"},
+		{name: "completed not stamped", text: "x 2025-10-14 Call dentist", stamp: true, want: "x 2025-10-14 Call dentist"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newTask(tt.text, tt.stamp, now).String(); got != tt.want {
+				t.Errorf("newTask(%q, %v) = %q, want %q", tt.text, tt.stamp, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"tada/internal/todo"
+
+	"github.com/spf13/cobra"
+)
+
+var archiveLsFormat string
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "Browse and restore archived tasks",
+	Long: `Work with the completed tasks that were moved to the archive files next
+to todo.txt. Archived tasks are numbered across all archive files, in file
+name order; use the numbers from tada archive ls with tada archive restore.`,
+}
+
+// archivedItem is the JSON representation of an archived task with its number
+type archivedItem struct {
+	ID   int    `json:"id"`
+	File string `json:"file"`
+	Text string `json:"text"`
+	todo.Item
+}
+
+var archiveLsCmd = &cobra.Command{
+	Use:     "ls [query...]",
+	Aliases: []string{"list"},
+	Short:   "List archived tasks",
+	Long: `Print the tasks of all archive files, grouped by file. Each task is
+prefixed with its number for tada archive restore. A query narrows the list
+to tasks matching all of its terms, see tada ls --help for the syntax.
+
+Examples:
+  tada archive ls
+  tada archive ls +garden 'completed:>=2025-01-01'
+  tada archive ls --format json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := validateFormat(archiveLsFormat); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		query, err := todo.ParseQuery(strings.Join(args, " "))
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+
+		archived := mustLoadArchives()
+		var items []archivedItem
+		for idx, item := range archived {
+			if query.Match(item.Item) {
+				items = append(items, archivedItem{ID: idx + 1, File: filepath.Base(item.File), Text: item.String(), Item: item.Item})
+			}
+		}
+
+		if archiveLsFormat != formatText {
+			if err := writeJSON(os.Stdout, archiveLsFormat, items); err != nil {
+				fmt.Fprintln(os.Stderr, "Error writing output:", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		width := len(fmt.Sprint(len(archived)))
+		for i, item := range items {
+			if i == 0 || item.File != items[i-1].File {
+				if i > 0 {
+					fmt.Println()
+				}
+				fmt.Println(item.File)
+			}
+			fmt.Printf("  %*d %s\n", width, item.ID, item.Text)
+		}
+	},
+}
+
+var archiveRestoreCmd = &cobra.Command{
+	Use:   "restore <id>...",
+	Short: "Move archived tasks back into todo.txt",
+	Long: `Move archived tasks, addressed by their number in tada archive ls, back
+to the end of todo.txt. Restored tasks are open again: their completion mark
+and date are removed. The restore is recorded in the journal and can be
+reverted with tada undo.
+
+Example:
+  tada archive restore 3 7`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		todoFile := mustTodoFile()
+
+		ids := make([]int, len(args))
+		for i, arg := range args {
+			id, err := strconv.Atoi(arg)
+			if err != nil {
+				fmt.Printf("Error: invalid task number %q\n", arg)
+				os.Exit(1)
+			}
+			ids[i] = id
+		}
+
+		// The numbers are looked up in the archive while todo.txt is locked
+		entry, err := todo.RestoreArchivedIDs(todoFile, mustArchive(mustLoadConfig(), todoFile), ids)
+		if err != nil && !warnJournal(err) {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+
+		for _, change := range entry.Changes {
+			if change.After != "" {
+				fmt.Printf("Restored %d %s\n", change.Line, change.After)
+			}
+		}
+	},
+}
+
+// mustLoadArchives loads the tasks of all archive files next to todo.txt,
+// exiting on errors
+func mustLoadArchives() []todo.ArchivedItem {
+	archived, err := todo.LoadArchives(filepath.Dir(mustTodoFile()))
+	if err != nil {
+		fmt.Println("Error loading archive:", err)
+		os.Exit(1)
+	}
+	return archived
+}
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+	archiveCmd.AddCommand(archiveLsCmd)
+	archiveCmd.AddCommand(archiveRestoreCmd)
+	addFormatFlag(archiveLsCmd, &archiveLsFormat, formatText)
+}
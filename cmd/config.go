@@ -3,7 +3,8 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path/filepath"
+	"strings"
+	"text/tabwriter"
 
 	"tada/internal/config"
 
@@ -13,97 +14,90 @@ import (
 var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Manage tada configuration",
-	Long:  `Configure tada settings like todo directory location.`,
+	Long: `Configure tada settings like todo directory location.
+
+Run tada config list to see all settings with their values and defaults.`,
 }
 
 var configSetCmd = &cobra.Command{
-	Use:   "set <key> <value>",
-	Short: "Set a configuration value",
-	Long:  `Set a configuration value. Available keys: dir`,
-	Args:  cobra.ExactArgs(2),
+	Use:               "set <key> <value>",
+	Short:             "Set a configuration value",
+	Long:              `Set a configuration value. The value is validated before it is saved.`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeSetting,
 	Run: func(cmd *cobra.Command, args []string) {
-		key := args[0]
-		value := args[1]
+		cfg := mustLoadConfig()
+		s := mustFindSetting(args[0])
 
-		cfg, err := config.Load()
+		value, err := s.parse(cfg, args[1])
 		if err != nil {
-			fmt.Println("Error loading config:", err)
+			fmt.Println("Error:", err)
 			os.Exit(1)
 		}
+		s.set(cfg, value)
+		mustSaveConfig(cfg, fmt.Sprintf("Set %s to: %s", s.key, value))
+	},
+}
 
-		switch key {
-		case "dir":
-			// Expand home directory if present
-			if len(value) >= 2 && value[:2] == "~/" {
-				home, err := os.UserHomeDir()
-				if err != nil {
-					fmt.Println("Error getting home directory:", err)
-					os.Exit(1)
-				}
-				value = filepath.Join(home, value[2:])
-			}
+var configGetCmd = &cobra.Command{
+	Use:               "get [key]",
+	Short:             "Get a configuration value",
+	Long:              `Get a configuration value, or its default if it is not set. If no key is specified, shows all config.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeSetting,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := mustLoadConfig()
 
-			// Convert to absolute path
-			absPath, err := filepath.Abs(value)
-			if err != nil {
-				fmt.Println("Error resolving path:", err)
-				os.Exit(1)
+		if len(args) == 0 {
+			// Show all config
+			configPath, _ := config.GetConfigPath()
+			fmt.Printf("Configuration file: %s\n\n", configPath)
+			for _, s := range settings {
+				fmt.Printf("%s: %s\n", s.key, displayValue(s.value(cfg)))
 			}
-
-			cfg.TodoDir = absPath
-			fmt.Printf("Set todo directory to: %s\n", absPath)
-		default:
-			fmt.Printf("Unknown config key: %s\n", key)
-			fmt.Println("Available keys: dir")
-			os.Exit(1)
+			return
 		}
 
-		if err := config.Save(cfg); err != nil {
-			fmt.Println("Error saving config:", err)
-			os.Exit(1)
-		}
+		s := mustFindSetting(args[0])
+		fmt.Println(displayValue(s.value(cfg)))
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:               "unset <key>",
+	Short:             "Reset a configuration value to its default",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSetting,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := mustLoadConfig()
+		s := mustFindSetting(args[0])
 
-		configPath, _ := config.GetConfigPath()
-		fmt.Printf("Configuration saved to: %s\n", configPath)
+		s.set(cfg, "")
+		mustSaveConfig(cfg, fmt.Sprintf("Reset %s to: %s", s.key, displayValue(s.def)))
 	},
 }
 
-var configGetCmd = &cobra.Command{
-	Use:   "get [key]",
-	Short: "Get a configuration value",
-	Long:  `Get a configuration value. If no key is specified, shows all config. Available keys: dir`,
-	Args:  cobra.MaximumNArgs(1),
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all settings with their values and descriptions",
+	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		cfg, err := config.Load()
-		if err != nil {
-			fmt.Println("Error loading config:", err)
-			os.Exit(1)
-		}
+		cfg := mustLoadConfig()
 
-		if len(args) == 0 {
-			// Show all config
-			configPath, _ := config.GetConfigPath()
-			fmt.Printf("Configuration file: %s\n\n", configPath)
-			if cfg.TodoDir != "" {
-				fmt.Printf("dir: %s\n", cfg.TodoDir)
-			} else {
-				fmt.Printf("dir: (not set)\n")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "KEY\tTYPE\tVALUE\tDESCRIPTION")
+		for _, s := range settings {
+			value := s.get(cfg)
+			if value == "" && s.def != "" {
+				value = s.def + " (default)"
 			}
-		} else {
-			key := args[0]
-			switch key {
-			case "dir":
-				if cfg.TodoDir != "" {
-					fmt.Println(cfg.TodoDir)
-				} else {
-					fmt.Println("(not set)")
-				}
-			default:
-				fmt.Printf("Unknown config key: %s\n", key)
-				fmt.Println("Available keys: dir")
-				os.Exit(1)
+			kind := s.kind
+			if len(s.values) > 0 && s.kind != "bool" {
+				kind = strings.Join(s.values, "|")
 			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.key, kind, displayValue(value), s.description)
 		}
+		_ = w.Flush()
 	},
 }
 
@@ -121,9 +115,70 @@ var configPathCmd = &cobra.Command{
 	},
 }
 
+// completeSetting completes setting keys, and the allowed values of the key
+// for tada config set
+func completeSetting(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return settingKeys(), cobra.ShellCompDirectiveNoFileComp
+	}
+	if cmd.Name() == "set" && len(args) == 1 {
+		if s, err := findSetting(args[0]); err == nil {
+			if s.kind == "path" {
+				return nil, cobra.ShellCompDirectiveFilterDirs
+			}
+			return s.values, cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// displayValue shows empty values as "(not set)"
+func displayValue(value string) string {
+	if value == "" {
+		return "(not set)"
+	}
+	return value
+}
+
+// mustLoadConfig loads the config file, exiting on errors
+func mustLoadConfig() *config.Config {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Println("Error loading config:", err)
+		os.Exit(1)
+	}
+	return cfg
+}
+
+// mustSaveConfig saves the config file, then prints message and where the
+// file is. It exits on errors.
+func mustSaveConfig(cfg *config.Config, message string) {
+	if err := config.Save(cfg); err != nil {
+		fmt.Println("Error saving config:", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(message)
+	configPath, _ := config.GetConfigPath()
+	fmt.Printf("Configuration saved to: %s\n", configPath)
+}
+
+// mustFindSetting returns the setting with the given key, exiting if there
+// is none
+func mustFindSetting(key string) setting {
+	s, err := findSetting(key)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	return s
+}
+
 func init() {
 	rootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(configSetCmd)
 	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configListCmd)
 	configCmd.AddCommand(configPathCmd)
 }
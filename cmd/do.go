@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"tada/internal/todo"
+
+	"github.com/spf13/cobra"
+)
+
+var doCmd = &cobra.Command{
+	Use:   "do <line>...",
+	Short: "Mark tasks as done by line number",
+	Long: `Mark one or more tasks as done, addressed by their line number in todo.txt
+(as shown by tada ls), or a range like 3-5. Recurring tasks get a fresh copy
+appended.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		todoFile := mustTodoFile()
+
+		var todos []todo.Item
+		var indexes []int
+		var lineCount int
+		err := todo.Update(todoFile, todo.OpComplete, func(loaded []todo.Item) ([]todo.Item, error) {
+			lineCount = len(loaded)
+			var err error
+			todos, indexes, err = completeLines(loaded, args, time.Now())
+			return todos, err
+		})
+		if err != nil && !warnJournal(err) {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+
+		for i := len(indexes) - 1; i >= 0; i-- {
+			fmt.Printf("%d %s\n", indexes[i]+1, todos[indexes[i]].String())
+		}
+		for i := lineCount; i < len(todos); i++ {
+			fmt.Printf("%d %s (recurring)\n", i+1, todos[i].String())
+		}
+	},
+}
+
+// completeLines marks the tasks on the lines given by args as done at now
+// It returns the todos, with copies of recurring tasks appended, and the
+// indexes of the completed tasks in descending order.
+func completeLines(todos []todo.Item, args []string, now time.Time) ([]todo.Item, []int, error) {
+	indexes, err := parseLineNumbers(args, todos)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, idx := range indexes {
+		todos = todo.Complete(todos, idx, now)
+	}
+	return todos, indexes, nil
+}
+
+func init() {
+	rootCmd.AddCommand(doCmd)
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"tada/internal/todo"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat string
+	exportJSON   bool
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export all tasks as JSON",
+	Long: `Print every task in todo.txt as JSON, including completed and future
+tasks. Each object holds the line number, the todo.txt text and the parsed
+fields: completion, priority, dates, contexts, projects and tags.
+
+Examples:
+  tada export --json | jq '.[] | select(.priority == "A")'
+  tada export --format jsonl`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if exportJSON {
+			exportFormat = formatJSON
+		}
+		if err := validateFormat(exportFormat); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+
+		todos, err := todo.LoadFromFile(mustTodoFile())
+		if err != nil {
+			fmt.Println("Error loading todo.txt:", err)
+			os.Exit(1)
+		}
+
+		if exportFormat == formatText {
+			for idx, item := range todos {
+				if !item.IsEmpty() {
+					fmt.Printf("%d %s\n", idx+1, item.String())
+				}
+			}
+			return
+		}
+
+		var items []lineItem
+		for idx, item := range todos {
+			if !item.IsEmpty() {
+				items = append(items, newLineItem(idx, item))
+			}
+		}
+		if err := writeJSON(os.Stdout, exportFormat, items); err != nil {
+			fmt.Fprintln(os.Stderr, "Error writing output:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	addFormatFlag(exportCmd, &exportFormat, formatJSON)
+	exportCmd.Flags().BoolVar(&exportJSON, "json", false, "Shorthand for --format json")
+}
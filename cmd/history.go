@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"tada/internal/todo"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyFormat string
+	historyLimit  int
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List the operations recorded in the journal",
+	Long: `List the changes made to todo.txt by tada, oldest first. Every add, edit,
+completion, deletion, priority change, archive and restore is recorded in
+todo_journal.jsonl next to todo.txt, with the lines before and after.
+
+Use the number in front of an operation with tada undo to revert it.
+
+Examples:
+  tada history
+  tada history -n 5
+  tada history --format json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := validateFormat(historyFormat); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+
+		entries, err := todo.LoadJournal(mustTodoFile())
+		if err != nil {
+			fmt.Println("Error loading journal:", err)
+			os.Exit(1)
+		}
+		if historyLimit > 0 && len(entries) > historyLimit {
+			entries = entries[len(entries)-historyLimit:]
+		}
+
+		if historyFormat != formatText {
+			if err := writeJSON(os.Stdout, historyFormat, entries); err != nil {
+				fmt.Fprintln(os.Stderr, "Error writing output:", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		for _, entry := range entries {
+			printJournalEntry(os.Stdout, entry)
+		}
+	},
+}
+
+// printJournalEntry prints an entry with its changes as a diff
+func printJournalEntry(w io.Writer, entry todo.JournalEntry) {
+	title := entry.Op
+	if entry.Reverts > 0 {
+		title = fmt.Sprintf("%s of %d", entry.Op, entry.Reverts)
+	}
+	// Entries the journal could not record have no ID yet
+	id := "-"
+	if entry.ID > 0 {
+		id = fmt.Sprint(entry.ID)
+	}
+	_, _ = fmt.Fprintf(w, "%s %s %s\n", id, entry.Time.Local().Format("2006-01-02 15:04"), title)
+
+	for _, change := range entry.Changes {
+		if change.Before != "" {
+			_, _ = fmt.Fprintf(w, "    - %d %s\n", change.Line, change.Before)
+		}
+		if change.After != "" {
+			_, _ = fmt.Fprintf(w, "    + %d %s\n", change.Line, change.After)
+		}
+	}
+	for _, line := range entry.Archived {
+		_, _ = fmt.Fprintf(w, "    > archived %s\n", line)
+	}
+	for _, line := range entry.Restored {
+		_, _ = fmt.Fprintf(w, "    < restored %s\n", line)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	addFormatFlag(historyCmd, &historyFormat, formatText)
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 0, "Only show the last N operations")
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"tada/internal/todo"
+)
+
+// parseLineNumbers converts line number arguments, single lines like 3 or
+// ranges like 3-5, into indexes of todos. Every line must exist and a single
+// line must hold a task, ranges skip blank lines. Duplicates are removed and
+// the indexes are returned in descending order, so removing them one by one
+// keeps the remaining indexes valid.
+func parseLineNumbers(args []string, todos []todo.Item) ([]int, error) {
+	seen := make(map[int]bool, len(args))
+	var indexes []int
+
+	for _, arg := range args {
+		from, to, isRange, err := parseLineRange(arg)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range []int{from, to} {
+			if line < 1 || line > len(todos) {
+				return nil, fmt.Errorf("no task on line %d (todo.txt has %d lines)", line, len(todos))
+			}
+		}
+
+		for line := from; line <= to; line++ {
+			if todos[line-1].IsEmpty() {
+				if isRange {
+					continue
+				}
+				return nil, fmt.Errorf("line %d is empty", line)
+			}
+			if !seen[line-1] {
+				seen[line-1] = true
+				indexes = append(indexes, line-1)
+			}
+		}
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(indexes)))
+	return indexes, nil
+}
+
+// parseLineRange parses a line number or a range of lines like 3-5
+func parseLineRange(arg string) (from, to int, isRange bool, err error) {
+	first, last, isRange := strings.Cut(arg, "-")
+	if !isRange {
+		last = first
+	}
+	from, fromErr := strconv.Atoi(first)
+	to, toErr := strconv.Atoi(last)
+	if fromErr != nil || toErr != nil {
+		return 0, 0, false, fmt.Errorf("invalid line number %q", arg)
+	}
+	if from > to {
+		return 0, 0, false, fmt.Errorf("invalid line range %q, the first line comes after the last", arg)
+	}
+	return from, to, isRange, nil
+}
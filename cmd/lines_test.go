@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"tada/internal/todo"
+)
+
+// parseTodos parses todo.txt lines
+func parseTodos(lines ...string) []todo.Item {
+	todos := make([]todo.Item, len(lines))
+	for i, line := range lines {
+		todos[i] = todo.Parse(line)
+	}
+	return todos
+}
+
+func TestParseLineNumbers(t *testing.T) {
+	todos := parseTodos("First", "Second", "", "Fourth", "Fifth")
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []int
+		wantErr bool
+	}{
+		{name: "single line", args: []string{"2"}, want: []int{1}},
+		{name: "descending order", args: []string{"1", "4", "2"}, want: []int{3, 1, 0}},
+		{name: "duplicates", args: []string{"2", "2", "1"}, want: []int{1, 0}},
+		{name: "range", args: []string{"1-2"}, want: []int{1, 0}},
+		{name: "range skips blank lines", args: []string{"2-4"}, want: []int{3, 1}},
+		{name: "single line range", args: []string{"4-4"}, want: []int{3}},
+		{name: "overlapping ranges", args: []string{"1-2", "2-4", "1"}, want: []int{3, 1, 0}},
+		{name: "zero", args: []string{"0"}, wantErr: true},
+		{name: "negative", args: []string{"-1"}, wantErr: true},
+		{name: "out of range", args: []string{"6"}, wantErr: true},
+		{name: "range past the end", args: []string{"4-6"}, wantErr: true},
+		{name: "range starting at zero", args: []string{"0-2"}, wantErr: true},
+		{name: "reversed range", args: []string{"4-2"}, wantErr: true},
+		{name: "open range", args: []string{"2-"}, wantErr: true},
+		{name: "blank line", args: []string{"3"}, wantErr: true},
+		{name: "not a number", args: []string{"two"}, wantErr: true},
+		{name: "one bad argument fails all", args: []string{"1", "9"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLineNumbers(tt.args, todos)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseLineNumbers(%q) = %v, want an error", tt.args, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseLineNumbers(%q) error = %v", tt.args, err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parseLineNumbers(%q) = %v, want %v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompleteLines(t *testing.T) {
+	now := time.Date(2025, 10, 17, 9, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "one task",
+			args: []string{"1"},
+			want: []string{"x 2025-10-17 Report", "Water plants due:2025-10-17 rec:3d", "x 2025-10-01 Old"},
+		},
+		{
+			name: "recurring task gets a copy",
+			args: []string{"2"},
+			want: []string{"Report", "x 2025-10-17 Water plants due:2025-10-17 rec:3d", "x 2025-10-01 Old", "2025-10-17 Water plants due:2025-10-20 rec:3d"},
+		},
+		{
+			name: "completed task stays as it is",
+			args: []string{"3"},
+			want: []string{"Report", "Water plants due:2025-10-17 rec:3d", "x 2025-10-01 Old"},
+		},
+		{name: "missing line", args: []string{"4"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todos := parseTodos("Report", "Water plants due:2025-10-17 rec:3d", "x 2025-10-01 Old")
+			got, _, err := completeLines(todos, tt.args, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("completeLines(%q) should fail", tt.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("completeLines(%q) error = %v", tt.args, err)
+			}
+			if lines := todo.Lines(got); !slices.Equal(lines, tt.want) {
+				t.Errorf("completeLines(%q) = %q, want %q", tt.args, lines, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeleteLines(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		want        []string
+		wantRemoved []lineItem
+		wantErr     bool
+	}{
+		{
+			name:        "lines refer to the file before deletion",
+			args:        []string{"3", "1"},
+			want:        []string{"", "Second", ""},
+			wantRemoved: []lineItem{{Line: 1, Text: "First"}, {Line: 3, Text: "Third"}},
+		},
+		{
+			name:        "range",
+			args:        []string{"2-3"},
+			want:        []string{"First", "", ""},
+			wantRemoved: []lineItem{{Line: 2, Text: "Second"}, {Line: 3, Text: "Third"}},
+		},
+		{name: "missing line", args: []string{"1", "4"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, removed, err := deleteLines(parseTodos("First", "Second", "Third"), tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("deleteLines(%q) should fail", tt.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("deleteLines(%q) error = %v", tt.args, err)
+			}
+			if lines := todo.Lines(got); !slices.Equal(lines, tt.want) {
+				t.Errorf("deleteLines(%q) = %q, want %q", tt.args, lines, tt.want)
+			}
+			if len(removed) != len(tt.wantRemoved) {
+				t.Fatalf("deleteLines(%q) removed %d tasks, want %d", tt.args, len(removed), len(tt.wantRemoved))
+			}
+			for i, item := range removed {
+				if item.Line != tt.wantRemoved[i].Line || item.Text != tt.wantRemoved[i].Text {
+					t.Errorf("Removed task %d = %d %q, want %d %q", i, item.Line, item.Text, tt.wantRemoved[i].Line, tt.wantRemoved[i].Text)
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteLines_KeepsLineNumbers(t *testing.T) {
+	// Like todo.sh, tada rm 2 && tada do 3 completes the task that was on line 3
+	todos, _, err := deleteLines(parseTodos("First", "Second", "Third"), []string{"2"})
+	if err != nil {
+		t.Fatalf("deleteLines() error = %v", err)
+	}
+	todos, _, err = completeLines(todos, []string{"3"}, time.Date(2025, 10, 17, 9, 0, 0, 0, time.Local))
+	if err != nil {
+		t.Fatalf("completeLines() error = %v", err)
+	}
+	if lines := todo.Lines(todos); !slices.Equal(lines, []string{"First", "", "x 2025-10-17 Third"}) {
+		t.Errorf("Lines after rm 2 and do 3 = %q", lines)
+	}
+
+	// The blank line is no task any more
+	if _, _, err := deleteLines(todos, []string{"2"}); err == nil {
+		t.Error("Deleting a blank line should fail")
+	}
+}
+
+func TestPrioritizeLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		priority string
+		want     []string
+		wantErr  bool
+	}{
+		{name: "set", args: []string{"2"}, priority: "a", want: []string{"(B) Report", "(A) Slides", "Budget"}},
+		{name: "range", args: []string{"2-3"}, priority: "C", want: []string{"(B) Report", "(C) Slides", "(C) Budget"}},
+		{name: "remove", args: []string{"1"}, priority: "", want: []string{"Report", "Slides", "Budget"}},
+		{name: "invalid priority", args: []string{"1"}, priority: "AB", wantErr: true},
+		{name: "missing line", args: []string{"4"}, priority: "A", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := prioritizeLines(parseTodos("(B) Report", "Slides", "Budget"), tt.args, tt.priority)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("prioritizeLines(%q, %q) should fail", tt.args, tt.priority)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("prioritizeLines(%q, %q) error = %v", tt.args, tt.priority, err)
+			}
+			if lines := todo.Lines(got); !slices.Equal(lines, tt.want) {
+				t.Errorf("prioritizeLines(%q, %q) = %q, want %q", tt.args, tt.priority, lines, tt.want)
+			}
+		})
+	}
+}
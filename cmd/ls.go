@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"tada/internal/todo"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	lsContexts []string
+	lsProjects []string
+	lsPriority string
+	lsDone     bool
+	lsPending  bool
+	lsAll      bool
+	lsFormat   string
+	lsGroup    string
+)
+
+var lsCmd = &cobra.Command{
+	Use:     "ls [query...]",
+	Aliases: []string{"list"},
+	Short:   "List tasks grouped like the TUI",
+	Long: `Print tasks to stdout, grouped like the TUI: by context unless --group or
+the group_by setting says otherwise.
+
+Each task is prefixed with its line number in todo.txt. A query narrows
+the list to tasks matching all of its terms, the same filter language as
+:filter in the TUI:
+
+  @context +project     tasks with the context or project
+  pri:A  pri:A-C        tasks with the priority, or in the range
+  pri:<=B               compares letters, so A and B
+  due:<today            due before today; also t:, created:, completed:
+                        with today, tomorrow, yesterday, YYYY-MM-DD, +3d
+  done                  completed tasks
+  key:value             tasks with the tag
+  text                  tasks containing the text (case-insensitive)
+
+Prefix a term with not: to negate it, e.g. not:done. A leading - works as
+well after --, which ends the flags: tada ls -- -done.
+
+With --format json or jsonl the matching tasks are printed as JSON objects
+in todo.txt order instead of grouped text.
+
+Examples:
+  tada ls
+  tada ls -c Work -P A-B
+  tada ls --group due
+  tada ls --done dentist
+  tada ls @work pri:<=B 'due:<today' not:done`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := validateFormat(lsFormat); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		if lsDone && lsPending {
+			fmt.Println("Error: --done and --pending cannot be combined")
+			os.Exit(1)
+		}
+
+		query, err := newLsQuery(args)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+
+		todoFile := mustTodoFile()
+		todos, err := todo.LoadFromFile(todoFile)
+		if err != nil {
+			fmt.Println("Error loading todo.txt:", err)
+			os.Exit(1)
+		}
+
+		cfg := mustLoadConfig()
+		archive := mustArchive(cfg, todoFile)
+		opts := todo.ViewOptions{
+			ShowFuture:       lsAll,
+			ShowOldCompleted: lsAll,
+			ArchiveAge:       &archive.Age,
+			Filter:           query.Match,
+		}
+		if lsFormat != formatText {
+			// Machine readable output is flat, in todo.txt order
+			var items []lineItem
+			for idx, item := range todos {
+				if opts.IsVisible(item) {
+					items = append(items, newLineItem(idx, item))
+				}
+			}
+			if err := writeJSON(os.Stdout, lsFormat, items); err != nil {
+				fmt.Fprintln(os.Stderr, "Error writing output:", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		printGroups(todo.GroupTodos(todos, mustGrouping(cfg, lsGroup), opts), len(todos))
+	},
+}
+
+// newLsQuery builds the query from the ls flags and query terms, the flags
+// are shorthands for query terms
+func newLsQuery(terms []string) (todo.Query, error) {
+	terms = slices.Clone(terms)
+	for _, context := range lsContexts {
+		terms = append(terms, "@"+strings.TrimPrefix(context, "@"))
+	}
+	for _, project := range lsProjects {
+		terms = append(terms, "+"+strings.TrimPrefix(project, "+"))
+	}
+	if lsPriority != "" {
+		terms = append(terms, "pri:"+lsPriority)
+	}
+	if lsDone {
+		terms = append(terms, "done")
+	}
+	if lsPending {
+		terms = append(terms, "not:done")
+	}
+	return todo.ParseQuery(strings.Join(terms, " "))
+}
+
+// printGroups prints grouped todos with their line numbers
+func printGroups(groups []todo.Group, total int) {
+	width := len(fmt.Sprint(total))
+
+	for i, group := range groups {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s (%d)\n", group.Title, len(group.Todos))
+		for _, t := range group.Todos {
+			fmt.Printf("  %*d %s\n", width, t.Index+1, t.Item.String())
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(lsCmd)
+	lsCmd.Flags().StringSliceVarP(&lsContexts, "context", "c", nil, "Only tasks with this context (repeatable)")
+	lsCmd.Flags().StringSliceVarP(&lsProjects, "project", "p", nil, "Only tasks with this project (repeatable)")
+	lsCmd.Flags().StringVarP(&lsPriority, "priority", "P", "", "Only tasks with this priority or range, e.g. A or A-C")
+	lsCmd.Flags().BoolVar(&lsDone, "done", false, "Only completed tasks")
+	lsCmd.Flags().BoolVar(&lsPending, "pending", false, "Only open tasks")
+	lsCmd.Flags().BoolVarP(&lsAll, "all", "a", false, "Include future and archivable completed tasks")
+	lsCmd.Flags().StringVarP(&lsGroup, "group", "g", "", "Group by context, project, priority, due, created or none")
+	_ = lsCmd.RegisterFlagCompletionFunc("group", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return groupingNames(), cobra.ShellCompDirectiveNoFileComp
+	})
+	addFormatFlag(lsCmd, &lsFormat, formatText)
+}
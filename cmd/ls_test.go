@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+
+	"tada/internal/todo"
+)
+
+func TestNewLsQuery(t *testing.T) {
+	lines := []string{
+		"(A) Report @Work +Q4",
+		"(C) Slides @work",
+		"x 2025-10-10 Invoice @Work +Q4",
+		"Groceries @Home",
+		"(B) Budget +Q4",
+	}
+
+	tests := []struct {
+		name     string
+		terms    []string
+		contexts []string
+		projects []string
+		priority string
+		done     bool
+		pending  bool
+		want     []int // Indexes of the matching lines
+		wantErr  bool
+	}{
+		{name: "everything", want: []int{0, 1, 2, 3, 4}},
+		{name: "context flag", contexts: []string{"work"}, want: []int{0, 1, 2}},
+		{name: "context flag with @", contexts: []string{"@Home"}, want: []int{3}},
+		{name: "all contexts must match", contexts: []string{"Work", "Home"}},
+		{name: "project flag", projects: []string{"+q4"}, want: []int{0, 2, 4}},
+		{name: "priority", priority: "a", want: []int{0}},
+		{name: "priority range", priority: "A-B", want: []int{0, 4}},
+		{name: "invalid priority range", priority: "C-A", wantErr: true},
+		{name: "done", done: true, want: []int{2}},
+		{name: "pending", pending: true, want: []int{0, 1, 3, 4}},
+		{name: "query terms", terms: []string{"@work", "not:done"}, want: []int{0, 1}},
+		{name: "text", terms: []string{"REPORT"}, want: []int{0}},
+		{name: "flags and terms", contexts: []string{"Work"}, terms: []string{"pri:<=C"}, want: []int{0, 1}},
+		{name: "invalid term", terms: []string{"due:someday"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lsContexts, lsProjects, lsPriority, lsDone, lsPending = tt.contexts, tt.projects, tt.priority, tt.done, tt.pending
+			t.Cleanup(func() {
+				lsContexts, lsProjects, lsPriority, lsDone, lsPending = nil, nil, "", false, false
+			})
+
+			query, err := newLsQuery(tt.terms)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("newLsQuery() = %q, want an error", query)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newLsQuery() error = %v", err)
+			}
+
+			var got []int
+			for idx, line := range lines {
+				if query.Match(todo.Parse(line)) {
+					got = append(got, idx)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("newLsQuery() %q matches lines %v, want %v", query, got, tt.want)
+			}
+		})
+	}
+}
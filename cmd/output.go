@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"tada/internal/todo"
+
+	"github.com/spf13/cobra"
+)
+
+// Output formats understood by read commands
+const (
+	formatText  = "text"
+	formatJSON  = "json"
+	formatJSONL = "jsonl"
+)
+
+// lineItem is the JSON representation of a task with its line number
+type lineItem struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+	todo.Item
+}
+
+// newLineItem wraps the todo at idx for JSON output
+func newLineItem(idx int, item todo.Item) lineItem {
+	return lineItem{Line: idx + 1, Text: item.String(), Item: item}
+}
+
+// addFormatFlag registers the --format flag on a read command
+func addFormatFlag(cmd *cobra.Command, target *string, defaultFormat string) {
+	cmd.Flags().StringVarP(target, "format", "o", defaultFormat, "Output format: text, json or jsonl")
+	_ = cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{formatText, formatJSON, formatJSONL}, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// validateFormat returns an error for unknown output formats
+func validateFormat(format string) error {
+	switch format {
+	case formatText, formatJSON, formatJSONL:
+		return nil
+	}
+	return fmt.Errorf("unknown format %q, use %s", format, strings.Join([]string{formatText, formatJSON, formatJSONL}, ", "))
+}
+
+// writeJSON writes values as a JSON array or as JSON Lines
+func writeJSON[T any](w io.Writer, format string, values []T) error {
+	encoder := json.NewEncoder(w)
+
+	if format == formatJSONL {
+		for _, value := range values {
+			if err := encoder.Encode(value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	encoder.SetIndent("", "  ")
+	if values == nil {
+		values = []T{}
+	}
+	return encoder.Encode(values)
+}
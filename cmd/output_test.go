@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"tada/internal/todo"
+)
+
+func TestLineItemJSON(t *testing.T) {
+	item := newLineItem(2, todo.Parse("x 2025-09-25 2025-09-24 Review post @Work +Blog due:2025-09-30"))
+
+	data, err := json.Marshal(item)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	// The fields of the todo are inlined next to the line number and text
+	expected := map[string]any{
+		"line":            float64(3),
+		"text":            "x 2025-09-25 2025-09-24 Review post @Work +Blog due:2025-09-30",
+		"completed":       true,
+		"completion_date": "2025-09-25",
+		"creation_date":   "2025-09-24",
+		"description":     "Review post @Work +Blog due:2025-09-30",
+		"contexts":        []any{"Work"},
+		"projects":        []any{"Blog"},
+		"tags":            map[string]any{"due": "2025-09-30"},
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("JSON = %s", data)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	items := []lineItem{
+		newLineItem(0, todo.Parse("(A) First")),
+		newLineItem(2, todo.Parse("Third")),
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		items  []lineItem
+		want   string
+	}{
+		{
+			name:   "json array",
+			format: formatJSON,
+			items:  items,
+			want: `[
+  {
+    "line": 1,
+    "text": "(A) First",
+    "completed": false,
+    "priority": "A",
+    "description": "First",
+    "contexts": [],
+    "projects": [],
+    "tags": {}
+  },
+  {
+    "line": 3,
+    "text": "Third",
+    "completed": false,
+    "description": "Third",
+    "contexts": [],
+    "projects": [],
+    "tags": {}
+  }
+]
+`,
+		},
+		{
+			name:   "json lines",
+			format: formatJSONL,
+			items:  items,
+			want: `{"line":1,"text":"(A) First","completed":false,"priority":"A","description":"First","contexts":[],"projects":[],"tags":{}}
+{"line":3,"text":"Third","completed":false,"description":"Third","contexts":[],"projects":[],"tags":{}}
+`,
+		},
+		{name: "empty json is an array", format: formatJSON, want: "[]\n"},
+		{name: "empty json lines", format: formatJSONL, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeJSON(&buf, tt.format, tt.items); err != nil {
+				t.Fatalf("writeJSON() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("writeJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	for _, format := range []string{formatText, formatJSON, formatJSONL} {
+		if err := validateFormat(format); err != nil {
+			t.Errorf("validateFormat(%q) error = %v", format, err)
+		}
+	}
+	if err := validateFormat("yaml"); err == nil || !strings.Contains(err.Error(), "yaml") {
+		t.Errorf("validateFormat(\"yaml\") error = %v, want an unknown format error", err)
+	}
+}
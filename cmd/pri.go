@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"tada/internal/todo"
+
+	"github.com/spf13/cobra"
+)
+
+var priCmd = &cobra.Command{
+	Use:   "pri <line>... <priority>",
+	Short: "Set the priority of tasks by line number",
+	Long: `Set the priority of one or more tasks, addressed by their line number in
+todo.txt (as shown by tada ls), or a range like 3-5. Use - as priority to
+remove it.
+
+Examples:
+  tada pri 3 A
+  tada pri 3 5 8 b
+  tada pri 3-5 C
+  tada pri 3 -`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		priority := args[len(args)-1]
+		if priority == "-" {
+			priority = ""
+		}
+
+		todoFile := mustTodoFile()
+
+		var todos []todo.Item
+		var indexes []int
+		err := todo.Update(todoFile, todo.OpPriority, func(loaded []todo.Item) ([]todo.Item, error) {
+			var err error
+			todos, indexes, err = prioritizeLines(loaded, args[:len(args)-1], priority)
+			return todos, err
+		})
+		if err != nil && !warnJournal(err) {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+
+		for i := len(indexes) - 1; i >= 0; i-- {
+			fmt.Printf("%d %s\n", indexes[i]+1, todos[indexes[i]].String())
+		}
+	},
+}
+
+// prioritizeLines sets the priority of the tasks on the lines given by args,
+// an empty priority removes it. It returns the todos and the indexes of the
+// changed tasks in descending order.
+func prioritizeLines(todos []todo.Item, args []string, priority string) ([]todo.Item, []int, error) {
+	indexes, err := parseLineNumbers(args, todos)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, idx := range indexes {
+		if err := todo.SetPriority(todos, idx, priority); err != nil {
+			return nil, nil, err
+		}
+	}
+	return todos, indexes, nil
+}
+
+func init() {
+	rootCmd.AddCommand(priCmd)
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"tada/internal/todo"
+
+	"github.com/spf13/cobra"
+)
+
+var rmCmd = &cobra.Command{
+	Use:     "rm <line>...",
+	Aliases: []string{"del"},
+	Short:   "Delete tasks by line number",
+	Long: `Delete one or more tasks, addressed by their line number in todo.txt
+(as shown by tada ls), or a range like 3-5. Like todo.sh, deleted lines are
+left blank, so the other tasks keep their line numbers.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		todoFile := mustTodoFile()
+
+		var removed []lineItem
+		err := todo.Update(todoFile, todo.OpDelete, func(todos []todo.Item) ([]todo.Item, error) {
+			var err error
+			todos, removed, err = deleteLines(todos, args)
+			return todos, err
+		})
+		if err != nil && !warnJournal(err) {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+
+		for _, item := range removed {
+			fmt.Printf("Deleted %d %s\n", item.Line, item.Text)
+		}
+	},
+}
+
+// deleteLines removes the tasks on the lines given by args
+// It returns the remaining todos and the removed tasks in line order.
+func deleteLines(todos []todo.Item, args []string) ([]todo.Item, []lineItem, error) {
+	indexes, err := parseLineNumbers(args, todos)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Indexes are in descending order, the removed tasks are listed in line order
+	removed := make([]lineItem, len(indexes))
+	for i, idx := range indexes {
+		removed[len(indexes)-1-i] = newLineItem(idx, todos[idx])
+		todos = todo.Remove(todos, idx)
+	}
+	return todos, removed, nil
+}
+
+func init() {
+	rootCmd.AddCommand(rmCmd)
+}
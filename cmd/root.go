@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 
 	"tada/internal/config"
+	"tada/internal/todo"
 	"tada/internal/tui"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -17,38 +19,18 @@ var rootCmd = &cobra.Command{
 	Short: "A vim-inspired todo list manager",
 	Long:  `tada is a terminal-based todo list manager using the todo.txt format with vim-inspired keybindings.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		// Get the todo directory from config
-		todoDir, err := config.GetTodoDir()
-		if err != nil {
-			fmt.Println("Error: No todo directory configured.")
-			fmt.Println()
-			fmt.Println("To get started, set your todo directory:")
-			fmt.Println("  tada config set dir /path/to/your/todo/directory")
-			fmt.Println()
-			fmt.Println("Example:")
-			fmt.Println("  tada config set dir ~/.tada")
-			os.Exit(1)
-		}
-
-		// Ensure the directory exists
-		if err := os.MkdirAll(todoDir, 0755); err != nil {
-			fmt.Println("Error creating todo directory:", err)
-			os.Exit(1)
-		}
-
-		// Get the full path to todo.txt
-		todoFile := filepath.Join(todoDir, "todo.txt")
-
-		// If todo.txt doesn't exist, create an empty one
-		if _, err := os.Stat(todoFile); os.IsNotExist(err) {
-			if err := os.WriteFile(todoFile, []byte(""), 0644); err != nil {
-				fmt.Println("Error creating todo.txt:", err)
-				os.Exit(1)
-			}
-		}
+		todoFile := mustTodoFile()
+		cfg := mustLoadConfig()
 
 		// Start the TUI
-		m := tui.NewModel(todoFile)
+		archive := mustArchive(cfg, todoFile)
+		m := tui.NewModel(todoFile, tui.Options{
+			GroupBy:   mustGrouping(cfg, ""),
+			Theme:     mustTheme(cfg),
+			Keymap:    mustKeymap(cfg),
+			DateOnAdd: cfg.DateOnAdd,
+			Archive:   &archive,
+		})
 		p := tea.NewProgram(m, tea.WithAltScreen())
 		if _, err := p.Run(); err != nil {
 			fmt.Println("Error running program:", err)
@@ -57,6 +39,111 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+// mustTodoFile returns the path to todo.txt, creating the configured directory
+// and an empty todo.txt if needed. It exits with a helpful message when no
+// todo directory is configured.
+func mustTodoFile() string {
+	// Get the todo directory from config
+	todoDir, err := config.GetTodoDir()
+	if err != nil {
+		fmt.Println("Error: No todo directory configured.")
+		fmt.Println()
+		fmt.Println("To get started, set your todo directory:")
+		fmt.Println("  tada config set dir /path/to/your/todo/directory")
+		fmt.Println()
+		fmt.Println("Example:")
+		fmt.Println("  tada config set dir ~/.tada")
+		os.Exit(1)
+	}
+
+	// Ensure the directory exists
+	if err := os.MkdirAll(todoDir, 0755); err != nil {
+		fmt.Println("Error creating todo directory:", err)
+		os.Exit(1)
+	}
+
+	// Get the full path to todo.txt
+	todoFile, err := config.GetTodoFilePath()
+	if err != nil {
+		fmt.Println("Error getting todo file path:", err)
+		os.Exit(1)
+	}
+
+	// If todo.txt doesn't exist, create an empty one
+	if _, err := os.Stat(todoFile); os.IsNotExist(err) {
+		if err := os.WriteFile(todoFile, []byte(""), 0644); err != nil {
+			fmt.Println("Error creating todo.txt:", err)
+			os.Exit(1)
+		}
+	}
+
+	return todoFile
+}
+
+// mustGrouping parses a grouping, falling back to the configured default
+// when name is empty. It exits with an error for unknown groupings.
+func mustGrouping(cfg *config.Config, name string) todo.Grouping {
+	if name == "" {
+		name = cfg.GroupBy
+	}
+
+	grouping, err := todo.ParseGrouping(name)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	return grouping
+}
+
+// mustTheme builds the theme from the config
+// It exits with an error if the theme section is invalid.
+func mustTheme(cfg *config.Config) *tui.Theme {
+	theme, err := tui.LoadTheme(cfg.Theme.Preset, cfg.Theme.Colors)
+	if err != nil {
+		fmt.Println("Error in theme config:", err)
+		os.Exit(1)
+	}
+	return &theme
+}
+
+// mustKeymap builds the key bindings from the config
+// It exits with an error if a binding is invalid.
+func mustKeymap(cfg *config.Config) *tui.Keymap {
+	keymap, err := tui.LoadKeymap(cfg.LeaderKey, cfg.Keymap)
+	if err != nil {
+		fmt.Println("Error in keymap config:", err)
+		os.Exit(1)
+	}
+	return &keymap
+}
+
+// mustArchive returns the archive settings for todoFile from the config
+// It exits with an error if the archive layout is invalid.
+func mustArchive(cfg *config.Config, todoFile string) todo.Archive {
+	archive := todo.DefaultArchive(filepath.Dir(todoFile))
+	if cfg.ArchiveAge != nil {
+		archive.Age = *cfg.ArchiveAge
+	}
+	layout, err := todo.ParseArchiveLayout(cfg.ArchiveLayout)
+	if err != nil {
+		fmt.Println("Error in archive config:", err)
+		os.Exit(1)
+	}
+	archive.Layout = layout
+	return archive
+}
+
+// warnJournal reports err as a warning on stderr if it only means that the
+// journal could not be written, the change itself was saved. It returns
+// false for other errors, which the caller must handle.
+func warnJournal(err error) bool {
+	if !errors.Is(err, todo.ErrJournal) {
+		return false
+	}
+	fmt.Fprintln(os.Stderr, "Warning:", err)
+	return true
+}
+
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
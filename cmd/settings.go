@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"tada/internal/config"
+	"tada/internal/todo"
+	"tada/internal/tui"
+)
+
+// setting is a key of the config file that tada config understands
+type setting struct {
+	key         string
+	kind        string // Type of the value, shown by tada config list
+	description string
+	def         string   // Value used when the setting is not set
+	values      []string // Allowed values, for validation and shell completion
+
+	// parse validates a value and returns it in the form it is stored in
+	parse func(cfg *config.Config, value string) (string, error)
+	get   func(cfg *config.Config) string // Empty if not set
+	set   func(cfg *config.Config, value string)
+}
+
+// settings are all known config keys, in the order tada config list shows them
+var settings = []setting{
+	{
+		key:         "dir",
+		kind:        "path",
+		description: "Directory holding todo.txt, the journal and the archives",
+		parse: func(_ *config.Config, value string) (string, error) {
+			// Expand home directory if present
+			if strings.HasPrefix(value, "~/") {
+				home, err := os.UserHomeDir()
+				if err != nil {
+					return "", fmt.Errorf("can't expand ~: %w", err)
+				}
+				value = filepath.Join(home, value[2:])
+			}
+			return filepath.Abs(value)
+		},
+		get: func(cfg *config.Config) string { return cfg.TodoDir },
+		set: func(cfg *config.Config, value string) { cfg.TodoDir = value },
+	},
+	{
+		key:         "group_by",
+		kind:        "choice",
+		description: "How the TUI and tada ls group tasks",
+		def:         string(todo.GroupContext),
+		values:      groupingNames(),
+		parse: func(_ *config.Config, value string) (string, error) {
+			grouping, err := todo.ParseGrouping(value)
+			return string(grouping), err
+		},
+		get: func(cfg *config.Config) string { return cfg.GroupBy },
+		set: func(cfg *config.Config, value string) { cfg.GroupBy = value },
+	},
+	{
+		key:         "date_on_add",
+		kind:        "bool",
+		description: "Add today's date to new tasks as their creation date",
+		def:         "false",
+		values:      []string{"true", "false"},
+		parse: func(_ *config.Config, value string) (string, error) {
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return "", fmt.Errorf("invalid value %q, use true or false", value)
+			}
+			return strconv.FormatBool(enabled), nil
+		},
+		get: func(cfg *config.Config) string {
+			if !cfg.DateOnAdd {
+				return ""
+			}
+			return "true"
+		},
+		set: func(cfg *config.Config, value string) { cfg.DateOnAdd = value == "true" },
+	},
+	{
+		key:         "theme",
+		kind:        "choice",
+		description: "Color theme of the TUI, single colors are set in the theme section",
+		def:         tui.DefaultPreset,
+		values:      tui.Presets(),
+		parse: func(cfg *config.Config, value string) (string, error) {
+			// Color overrides from the config file apply on top of the preset
+			_, err := tui.LoadTheme(value, cfg.Theme.Colors)
+			return value, err
+		},
+		get: func(cfg *config.Config) string { return cfg.Theme.Preset },
+		set: func(cfg *config.Config, value string) { cfg.Theme.Preset = value },
+	},
+	{
+		key:         "leader_key",
+		kind:        "key",
+		description: "Key starting the leader shortcuts of the TUI, like , or <space>",
+		def:         tui.DefaultLeader,
+		parse: func(cfg *config.Config, value string) (string, error) {
+			// Bindings from the config file must still work with the new leader
+			_, err := tui.LoadKeymap(value, cfg.Keymap)
+			return value, err
+		},
+		get: func(cfg *config.Config) string { return cfg.LeaderKey },
+		set: func(cfg *config.Config, value string) { cfg.LeaderKey = value },
+	},
+	{
+		key:         "archive_age",
+		kind:        "int",
+		description: "Days after completion before tasks are archived, 0 archives them right away",
+		def:         strconv.Itoa(todo.DefaultArchiveAge),
+		parse: func(_ *config.Config, value string) (string, error) {
+			age, err := strconv.Atoi(value)
+			if err != nil || age < 0 {
+				return "", fmt.Errorf("invalid value %q, use a number of days, 0 or more", value)
+			}
+			return strconv.Itoa(age), nil
+		},
+		get: func(cfg *config.Config) string {
+			if cfg.ArchiveAge == nil {
+				return ""
+			}
+			return strconv.Itoa(*cfg.ArchiveAge)
+		},
+		set: func(cfg *config.Config, value string) {
+			cfg.ArchiveAge = nil
+			if age, err := strconv.Atoi(value); err == nil {
+				cfg.ArchiveAge = &age
+			}
+		},
+	},
+	{
+		key:         "archive_layout",
+		kind:        "choice",
+		description: "How archive files are split: by month, by year, one done.txt or by project",
+		def:         string(todo.LayoutMonthly),
+		values:      archiveLayoutNames(),
+		parse: func(_ *config.Config, value string) (string, error) {
+			layout, err := todo.ParseArchiveLayout(value)
+			return string(layout), err
+		},
+		get: func(cfg *config.Config) string { return cfg.ArchiveLayout },
+		set: func(cfg *config.Config, value string) { cfg.ArchiveLayout = value },
+	},
+	{
+		key:         "editor",
+		kind:        "command",
+		description: "Editor command, $VISUAL, $EDITOR or vi if not set",
+		def:         config.DefaultEditor(),
+		parse: func(_ *config.Config, value string) (string, error) {
+			if strings.TrimSpace(value) == "" {
+				return "", fmt.Errorf("editor is empty, use tada config unset editor instead")
+			}
+			return value, nil
+		},
+		get: func(cfg *config.Config) string { return cfg.Editor },
+		set: func(cfg *config.Config, value string) { cfg.Editor = value },
+	},
+}
+
+// findSetting returns the setting with the given key
+func findSetting(key string) (setting, error) {
+	for _, s := range settings {
+		if s.key == key {
+			return s, nil
+		}
+	}
+	return setting{}, fmt.Errorf("unknown config key %q, use one of: %s", key, strings.Join(settingKeys(), ", "))
+}
+
+// settingKeys returns the keys of all settings
+func settingKeys() []string {
+	keys := make([]string, len(settings))
+	for i, s := range settings {
+		keys[i] = s.key
+	}
+	return keys
+}
+
+// value returns the value of the setting in cfg, or its default if unset
+func (s setting) value(cfg *config.Config) string {
+	if value := s.get(cfg); value != "" {
+		return value
+	}
+	return s.def
+}
+
+// groupingNames returns the names of all groupings
+func groupingNames() []string {
+	names := make([]string, len(todo.Groupings))
+	for i, grouping := range todo.Groupings {
+		names[i] = string(grouping)
+	}
+	return names
+}
+
+// archiveLayoutNames returns the names of all archive layouts
+func archiveLayoutNames() []string {
+	names := make([]string, len(todo.ArchiveLayouts))
+	for i, layout := range todo.ArchiveLayouts {
+		names[i] = string(layout)
+	}
+	return names
+}
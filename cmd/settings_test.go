@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"tada/internal/config"
+)
+
+func TestSettings_Parse(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatalf("UserHomeDir() error = %v", err)
+	}
+	relative, err := filepath.Abs("todos")
+	if err != nil {
+		t.Fatalf("Abs() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		cfg     config.Config
+		want    string
+		wantErr bool
+	}{
+		// path
+		{name: "dir in home", key: "dir", value: "~/todos", want: filepath.Join(home, "todos")},
+		{name: "relative dir", key: "dir", value: "todos", want: relative},
+
+		// choice
+		{name: "grouping", key: "group_by", value: "Project", want: "project"},
+		{name: "unknown grouping", key: "group_by", value: "weekday", wantErr: true},
+		{name: "theme", key: "theme", value: "light", want: "light"},
+		{name: "unknown theme", key: "theme", value: "neon", wantErr: true},
+		{
+			name:    "theme with invalid color override",
+			key:     "theme",
+			value:   "light",
+			cfg:     config.Config{Theme: config.ThemeConfig{Colors: map[string]string{"accent": "not a color"}}},
+			wantErr: true,
+		},
+		{name: "archive layout", key: "archive_layout", value: "Yearly", want: "yearly"},
+		{name: "unknown archive layout", key: "archive_layout", value: "weekly", wantErr: true},
+
+		// bool
+		{name: "bool", key: "date_on_add", value: "true", want: "true"},
+		{name: "bool as number", key: "date_on_add", value: "0", want: "false"},
+		{name: "invalid bool", key: "date_on_add", value: "sometimes", wantErr: true},
+
+		// key
+		{name: "leader", key: "leader_key", value: ",", want: ","},
+		{name: "named leader", key: "leader_key", value: "<space>", want: "<space>"},
+		{name: "leader of two keys", key: "leader_key", value: "ab", wantErr: true},
+		{
+			name:    "leader with an invalid binding",
+			key:     "leader_key",
+			value:   ",",
+			cfg:     config.Config{Keymap: map[string]string{"<leader>t": "no_such_action"}},
+			wantErr: true,
+		},
+
+		// int
+		{name: "age", key: "archive_age", value: "14", want: "14"},
+		{name: "age normalized", key: "archive_age", value: "007", want: "7"},
+		{name: "age zero", key: "archive_age", value: "0", want: "0"},
+		{name: "negative age", key: "archive_age", value: "-1", wantErr: true},
+		{name: "age not a number", key: "archive_age", value: "a week", wantErr: true},
+
+		// command
+		{name: "editor", key: "editor", value: "code --wait", want: "code --wait"},
+		{name: "empty editor", key: "editor", value: " ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := findSetting(tt.key)
+			if err != nil {
+				t.Fatalf("findSetting(%q) error = %v", tt.key, err)
+			}
+			got, err := s.parse(&tt.cfg, tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parse(%q) = %q, want an error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse(%q) error = %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("parse(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSettings_SetAndUnset(t *testing.T) {
+	values := map[string]string{
+		"dir":            "/tmp/todos",
+		"group_by":       "due",
+		"date_on_add":    "true",
+		"theme":          "light",
+		"leader_key":     ",",
+		"archive_age":    "0",
+		"archive_layout": "single",
+		"editor":         "nvim",
+	}
+
+	for _, s := range settings {
+		t.Run(s.key, func(t *testing.T) {
+			value, ok := values[s.key]
+			if !ok {
+				t.Fatalf("No test value for setting %q", s.key)
+			}
+
+			cfg := &config.Config{}
+			if got := s.value(cfg); got != s.def {
+				t.Errorf("value() when unset = %q, want the default %q", got, s.def)
+			}
+			s.set(cfg, value)
+			if got := s.value(cfg); got != value {
+				t.Errorf("value() after set = %q, want %q", got, value)
+			}
+			s.set(cfg, "")
+			if got := s.get(cfg); got != "" {
+				t.Errorf("get() after unset = %q, want it empty", got)
+			}
+		})
+	}
+}
+
+func TestFindSetting_Unknown(t *testing.T) {
+	if _, err := findSetting("sparkle"); err == nil {
+		t.Error("Expected an error for an unknown key")
+	}
+}
+
+func TestDefaultEditor(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	if got := config.DefaultEditor(); got != "vi" {
+		t.Errorf("DefaultEditor() without $VISUAL and $EDITOR = %q, want vi", got)
+	}
+	t.Setenv("EDITOR", "nano")
+	if got := config.DefaultEditor(); got != "nano" {
+		t.Errorf("DefaultEditor() = %q, want $EDITOR", got)
+	}
+	t.Setenv("VISUAL", "code --wait")
+	if got := config.DefaultEditor(); got != "code --wait" {
+		t.Errorf("DefaultEditor() = %q, want $VISUAL before $EDITOR", got)
+	}
+}
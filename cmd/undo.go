@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"tada/internal/todo"
+
+	"github.com/spf13/cobra"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo <operation>",
+	Short: "Revert an operation from the journal",
+	Long: `Revert an operation listed by tada history, even after tada was restarted.
+
+The lines the operation changed are put back as they were, other tasks are
+left alone. Archived tasks are taken out of the archive again. The revert is
+recorded in the journal as well, so it can be undone too.
+
+Examples:
+  tada undo 12`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Printf("Error: invalid operation number %q\n", args[0])
+			os.Exit(1)
+		}
+
+		todoFile := mustTodoFile()
+		entry, err := todo.RevertEntry(todoFile, id, mustArchive(mustLoadConfig(), todoFile))
+		if err != nil && !errors.Is(err, todo.ErrJournal) {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+
+		// The revert is done even if the journal could not record it
+		printJournalEntry(os.Stdout, entry)
+		warnJournal(err)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+}
@@ -9,7 +9,37 @@ import (
 )
 
 type Config struct {
-	TodoDir string `yaml:"todo_dir"`
+	TodoDir   string      `yaml:"todo_dir"`
+	GroupBy   string      `yaml:"group_by,omitempty"`    // Default grouping: context, project, priority, due, created or none
+	DateOnAdd bool        `yaml:"date_on_add,omitempty"` // Add the creation date to new tasks
+	Editor    string      `yaml:"editor,omitempty"`      // Editor command, DefaultEditor if empty
+	Theme     ThemeConfig `yaml:"theme,omitempty"`
+
+	// When and where completed tasks are archived
+	ArchiveAge    *int   `yaml:"archive_age,omitempty"`    // Days after completion, 5 if not set
+	ArchiveLayout string `yaml:"archive_layout,omitempty"` // monthly, yearly, single or project, monthly if empty
+
+	// Key bindings of the TUI on top of the defaults, like "<leader>t": top
+	LeaderKey string            `yaml:"leader_key,omitempty"` // <space> if empty
+	Keymap    map[string]string `yaml:"keymap,omitempty"`
+}
+
+// ThemeConfig selects the TUI colors: a bundled preset, with single colors
+// overridden by name. Colors are ANSI numbers (0-255) or hex (#ff8800).
+type ThemeConfig struct {
+	Preset string            `yaml:"preset,omitempty"` // dark or light, dark if empty
+	Colors map[string]string `yaml:"colors,omitempty"` // Overrides like accent: "#ff8800"
+}
+
+// DefaultEditor returns the editor used when none is configured: $VISUAL,
+// $EDITOR or vi
+func DefaultEditor() string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor := os.Getenv(name); editor != "" {
+			return editor
+		}
+	}
+	return "vi"
 }
 
 // GetConfigPath returns the path to the config file
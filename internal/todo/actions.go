@@ -0,0 +1,150 @@
+package todo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// IsEmpty returns true if the item holds no task, like a blank line in todo.txt
+func (i Item) IsEmpty() bool {
+	return strings.TrimSpace(i.String()) == ""
+}
+
+// Complete marks the todo at idx as completed on the given date
+// Recurring tasks get a fresh copy appended to the list, so the indexes of
+// existing todos do not change. Completing a completed todo is a no-op.
+func Complete(todos []Item, idx int, now time.Time) []Item {
+	if idx < 0 || idx >= len(todos) || todos[idx].Completed {
+		return todos
+	}
+
+	original := todos[idx]
+	completed := original
+	completed.Completed = true
+	completed.CompletionDate = now.Format("2006-01-02")
+	todos[idx] = completed.Normalize()
+
+	// Recurring tasks get a fresh copy
+	if next, ok := original.NextRecurrence(now); ok {
+		todos = append(todos, next)
+	}
+
+	return todos
+}
+
+// Reopen returns the todo marked as open again, without completion date
+func Reopen(item Item) Item {
+	item.Completed = false
+	item.CompletionDate = ""
+	return item.Normalize()
+}
+
+// Remove deletes the todo at idx
+// Like todo.sh, the line is left blank, so the todos below keep their line
+// numbers.
+func Remove(todos []Item, idx int) []Item {
+	if idx < 0 || idx >= len(todos) {
+		return todos
+	}
+	todos[idx] = Parse("")
+	return todos
+}
+
+// SetPriority sets the priority of the todo at idx
+// An empty priority removes it. Returns an error for anything but A-Z.
+func SetPriority(todos []Item, idx int, priority string) error {
+	if idx < 0 || idx >= len(todos) {
+		return fmt.Errorf("no todo at index %d", idx)
+	}
+
+	priority = strings.ToUpper(priority)
+	if priority != "" && (len(priority) != 1 || priority[0] < 'A' || priority[0] > 'Z') {
+		return fmt.Errorf("invalid priority %q, use a letter from A to Z", priority)
+	}
+
+	item := todos[idx]
+	item.Priority = priority
+	todos[idx] = item.Normalize()
+	return nil
+}
+
+// AddTokens adds @context, +project and key:value tokens to the todo at idx
+// Contexts and projects the todo already has are skipped, tags are set to
+// the new value.
+func AddTokens(todos []Item, idx int, tokens []string) error {
+	if idx < 0 || idx >= len(todos) {
+		return fmt.Errorf("no todo at index %d", idx)
+	}
+
+	item := todos[idx]
+	for _, token := range tokens {
+		switch {
+		case len(token) > 1 && strings.HasPrefix(token, "@"):
+			if !contains(item.Contexts, token[1:]) {
+				item.Contexts = append(item.Contexts, token[1:])
+			}
+		case len(token) > 1 && strings.HasPrefix(token, "+"):
+			if !contains(item.Projects, token[1:]) {
+				item.Projects = append(item.Projects, token[1:])
+			}
+		default:
+			key, value, ok := splitTag(token)
+			if !ok {
+				return fmt.Errorf("invalid token %q, use @context, +project or key:value", token)
+			}
+			item.SetTag(key, value)
+		}
+	}
+
+	todos[idx] = item.Normalize()
+	return nil
+}
+
+// RemoveTokens removes @context, +project and key:value tokens from the todo
+// at idx. Tags can be given by key only.
+func RemoveTokens(todos []Item, idx int, tokens []string) error {
+	if idx < 0 || idx >= len(todos) {
+		return fmt.Errorf("no todo at index %d", idx)
+	}
+
+	item := todos[idx]
+	for _, token := range tokens {
+		switch {
+		case len(token) > 1 && strings.HasPrefix(token, "@"):
+			item.Contexts = without(item.Contexts, token[1:])
+		case len(token) > 1 && strings.HasPrefix(token, "+"):
+			item.Projects = without(item.Projects, token[1:])
+		default:
+			key, _, _ := strings.Cut(token, ":")
+			if key == "" || strings.ContainsAny(key, "@+") {
+				return fmt.Errorf("invalid token %q, use @context, +project or key", token)
+			}
+			item.RemoveTag(key)
+		}
+	}
+
+	todos[idx] = item.Normalize()
+	return nil
+}
+
+// contains reports whether values holds value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// without returns values with every occurrence of value removed
+func without(values []string, value string) []string {
+	result := []string{}
+	for _, v := range values {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,131 @@
+package todo
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestComplete(t *testing.T) {
+	now := time.Date(2025, 10, 17, 9, 0, 0, 0, time.Local)
+
+	todos := []Item{
+		Parse("(A) 2025-10-01 Call dentist @Phone"),
+		Parse("Water plants due:2025-10-17 rec:3d"),
+		Parse("x 2025-10-10 Already done"),
+	}
+
+	todos = Complete(todos, 0, now)
+	if got := todos[0].String(); got != "x 2025-10-17 2025-10-01 Call dentist @Phone pri:A" {
+		t.Errorf("Completed item = %q", got)
+	}
+	if todos[0].Raw != todos[0].String() {
+		t.Errorf("Raw = %q, want it to match String()", todos[0].Raw)
+	}
+
+	todos = Complete(todos, 1, now)
+	if len(todos) != 4 {
+		t.Fatalf("Expected recurring copy to be appended, got %d todos", len(todos))
+	}
+	if got := todos[3].String(); got != "2025-10-17 Water plants due:2025-10-20 rec:3d" {
+		t.Errorf("Recurring copy = %q", got)
+	}
+
+	todos = Complete(todos, 2, now)
+	if got := todos[2].String(); got != "x 2025-10-10 Already done" {
+		t.Errorf("Completing a completed item changed it to %q", got)
+	}
+
+	if got := Complete(todos, 10, now); len(got) != len(todos) {
+		t.Error("Complete() with an invalid index should not change the list")
+	}
+}
+
+func TestRemove(t *testing.T) {
+	todos := []Item{Parse("One"), Parse("Two"), Parse("Three")}
+
+	// The line is left blank, so the lines below keep their numbers
+	todos = Remove(todos, 1)
+	if lines := Lines(todos); !slices.Equal(lines, []string{"One", "", "Three"}) {
+		t.Errorf("Remove() = %q", lines)
+	}
+
+	if got := Remove(todos, 5); len(got) != 3 {
+		t.Error("Remove() with an invalid index should not change the list")
+	}
+}
+
+func TestSetPriority(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		priority  string
+		expected  string
+		expectErr bool
+	}{
+		{name: "set priority", line: "2025-10-01 Call dentist", priority: "B", expected: "(B) 2025-10-01 Call dentist"},
+		{name: "change priority", line: "(A) Call dentist", priority: "c", expected: "(C) Call dentist"},
+		{name: "clear priority", line: "(A) Call dentist", priority: "", expected: "Call dentist"},
+		{name: "pri tag is rewritten", line: "Call dentist pri:A", priority: "D", expected: "Call dentist pri:D"},
+		{name: "invalid priority", line: "Call dentist", priority: "AA", expectErr: true},
+		{name: "non letter priority", line: "Call dentist", priority: "1", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todos := []Item{Parse(tt.line)}
+			err := SetPriority(todos, 0, tt.priority)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("SetPriority() should return an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetPriority() error = %v", err)
+			}
+			if got := todos[0].String(); got != tt.expected {
+				t.Errorf("SetPriority() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsEmpty(t *testing.T) {
+	if !Parse("").IsEmpty() || !Parse("   ").IsEmpty() {
+		t.Error("Blank lines should be empty")
+	}
+	if Parse("x").IsEmpty() || Parse("Task").IsEmpty() {
+		t.Error("Tasks should not be empty")
+	}
+}
+
+func TestAddTokens(t *testing.T) {
+	todos := []Item{Parse("(A) Call dentist @Phone")}
+
+	if err := AddTokens(todos, 0, []string{"@Phone", "@Errands", "+Health", "due:2025-10-20"}); err != nil {
+		t.Fatalf("AddTokens() error = %v", err)
+	}
+	if got := todos[0].String(); got != "(A) Call dentist @Phone @Errands +Health due:2025-10-20" {
+		t.Errorf("AddTokens() = %q", got)
+	}
+
+	if err := AddTokens(todos, 0, []string{"@Home", "nonsense"}); err == nil {
+		t.Error("AddTokens() should reject tokens that are not @context, +project or key:value")
+	}
+}
+
+func TestRemoveTokens(t *testing.T) {
+	todos := []Item{Parse("Call dentist @Phone @Errands +Health due:2025-10-20")}
+
+	if err := RemoveTokens(todos, 0, []string{"@Errands", "+Health", "due"}); err != nil {
+		t.Fatalf("RemoveTokens() error = %v", err)
+	}
+	if got := todos[0].String(); got != "Call dentist @Phone" {
+		t.Errorf("RemoveTokens() = %q", got)
+	}
+
+	if err := RemoveTokens(todos, 3, []string{"@Phone"}); err == nil {
+		t.Error("RemoveTokens() with an invalid index should fail")
+	}
+}
@@ -0,0 +1,402 @@
+package todo
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultArchiveAge is the number of days completed todos stay in todo.txt
+const DefaultArchiveAge = 5
+
+// ArchiveLayout decides which archive file a completed todo goes to
+type ArchiveLayout string
+
+// Archive layouts
+const (
+	LayoutMonthly ArchiveLayout = "monthly" // todo_archive_2025_01.txt by completion month
+	LayoutYearly  ArchiveLayout = "yearly"  // todo_archive_2025.txt by completion year
+	LayoutSingle  ArchiveLayout = "single"  // done.txt, as in the todo.txt convention
+	LayoutProject ArchiveLayout = "project" // todo_archive_project_<name>.txt by first project
+)
+
+// ArchiveLayouts lists all layouts in the order they are offered to users
+var ArchiveLayouts = []ArchiveLayout{LayoutMonthly, LayoutYearly, LayoutSingle, LayoutProject}
+
+// doneFilename is the archive file of the single layout
+const doneFilename = "done.txt"
+
+// ParseArchiveLayout parses the name of a layout, an empty name is LayoutMonthly
+func ParseArchiveLayout(name string) (ArchiveLayout, error) {
+	if name == "" {
+		return LayoutMonthly, nil
+	}
+	for _, layout := range ArchiveLayouts {
+		if strings.EqualFold(name, string(layout)) {
+			return layout, nil
+		}
+	}
+
+	names := make([]string, len(ArchiveLayouts))
+	for i, layout := range ArchiveLayouts {
+		names[i] = string(layout)
+	}
+	return "", fmt.Errorf("unknown archive layout %q, use one of: %s", name, strings.Join(names, ", "))
+}
+
+// Archive describes where and when completed todos are archived
+type Archive struct {
+	Dir    string        // Directory of the archive files, next to todo.txt
+	Age    int           // Days after completion before a todo is archived, 0 archives right away
+	Layout ArchiveLayout // How archived todos are split into files
+}
+
+// DefaultArchive returns the archive settings used unless configured
+// otherwise: monthly files, for todos completed more than 5 days ago
+func DefaultArchive(dir string) Archive {
+	return Archive{Dir: dir, Age: DefaultArchiveAge, Layout: LayoutMonthly}
+}
+
+// IsDue returns true if a todo is completed long enough ago to be archived
+func (a Archive) IsDue(item Item) bool {
+	return item.IsCompletedOlderThanDays(a.Age)
+}
+
+// Archive moves the todos that are due to archive files
+// Returns the remaining todos, the archived todos and any error
+func (a Archive) Archive(todos []Item) ([]Item, []Item, error) {
+	remainingTodos, archivedTodos := a.Split(todos)
+	if err := a.Append(archivedTodos); err != nil {
+		return nil, nil, err
+	}
+
+	return remainingTodos, archivedTodos, nil
+}
+
+// Split returns the todos that stay in todo.txt and the ones that are due to
+// be archived, without writing any archive file
+func (a Archive) Split(todos []Item) ([]Item, []Item) {
+	var remainingTodos []Item
+	var archivedTodos []Item
+
+	for _, item := range todos {
+		if a.IsDue(item) {
+			if _, ok := a.Filename(item); ok {
+				archivedTodos = append(archivedTodos, item)
+				continue
+			}
+			// If we can't parse the date, keep it in the main list
+			remainingTodos = append(remainingTodos, item)
+		} else {
+			remainingTodos = append(remainingTodos, item)
+		}
+	}
+
+	return remainingTodos, archivedTodos
+}
+
+// unsafeFileChars matches characters that don't belong in archive file names
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// Filename returns the archive file a completed todo belongs in
+// Returns false if the layout needs a completion date the todo doesn't have.
+func (a Archive) Filename(item Item) (string, bool) {
+	switch a.Layout {
+	case LayoutSingle:
+		return filepath.Join(a.Dir, doneFilename), true
+	case LayoutProject:
+		name := "no_project"
+		if len(item.Projects) > 0 {
+			name = "project_" + unsafeFileChars.ReplaceAllString(item.Projects[0], "_")
+		}
+		return filepath.Join(a.Dir, "todo_archive_"+name+".txt"), true
+	}
+
+	completionTime, err := time.Parse("2006-01-02", item.CompletionDate)
+	if err != nil {
+		return "", false
+	}
+	key := completionTime.Format("2006_01")
+	if a.Layout == LayoutYearly {
+		key = completionTime.Format("2006")
+	}
+	return filepath.Join(a.Dir, "todo_archive_"+key+".txt"), true
+}
+
+// groupByFile groups todos by the archive file they belong in
+// Todos the layout has no file for are skipped.
+func (a Archive) groupByFile(items []Item) map[string][]Item {
+	byFile := make(map[string][]Item)
+	for _, item := range items {
+		if filename, ok := a.Filename(item); ok {
+			byFile[filename] = append(byFile[filename], item)
+		}
+	}
+	return byFile
+}
+
+// Append appends completed todos to their archive files
+func (a Archive) Append(items []Item) error {
+	// Write each file's items to the appropriate archive file
+	for archiveFilename, items := range a.groupByFile(items) {
+		// Open file in append mode, create if doesn't exist
+		file, err := os.OpenFile(archiveFilename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open archive file %s: %w", archiveFilename, err)
+		}
+
+		writer := bufio.NewWriter(file)
+		for _, item := range items {
+			_, err := fmt.Fprintln(writer, item.String())
+			if err != nil {
+				_ = file.Close() // Best effort close on error path
+				return fmt.Errorf("failed to write to archive file %s: %w", archiveFilename, err)
+			}
+		}
+
+		if err := writer.Flush(); err != nil {
+			_ = file.Close() // Best effort close on error path
+			return fmt.Errorf("failed to flush archive file %s: %w", archiveFilename, err)
+		}
+
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("failed to close archive file %s: %w", archiveFilename, err)
+		}
+	}
+
+	return nil
+}
+
+// Remove removes todos from the archive files, the reverse of Append.
+// Todos are looked for in the file the layout puts them in first, then in
+// all other archive files, so changing the layout doesn't strand them.
+// Archive files that end up empty are deleted.
+func (a Archive) Remove(items []Item) error {
+	var missing []Item
+	checked := make(map[string]bool)
+	for archiveFilename, items := range a.groupByFile(items) {
+		notFound, err := removeLines(archiveFilename, items)
+		if err != nil {
+			return err
+		}
+		missing = append(missing, notFound...)
+		checked[archiveFilename] = true
+	}
+	for _, item := range items {
+		if _, ok := a.Filename(item); !ok {
+			missing = append(missing, item)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+	files, err := ArchiveFiles(a.Dir)
+	if err != nil {
+		return err
+	}
+	for _, archiveFilename := range files {
+		if len(missing) == 0 {
+			break
+		}
+		if checked[archiveFilename] {
+			continue
+		}
+		if missing, err = removeLines(archiveFilename, missing); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeLines removes the first matching line for each item from an archive
+// file and returns the items it doesn't contain. The file is deleted if it
+// ends up empty.
+func removeLines(archiveFilename string, items []Item) ([]Item, error) {
+	archived, err := LoadFromFile(archiveFilename)
+	if os.IsNotExist(err) {
+		return items, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive file %s: %w", archiveFilename, err)
+	}
+
+	var missing []Item
+	removed := false
+	for _, item := range items {
+		line := item.String()
+		found := false
+		for idx := range archived {
+			if archived[idx].String() == line {
+				archived = slices.Delete(archived, idx, idx+1)
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, item)
+		}
+		removed = removed || found
+	}
+	if !removed {
+		return missing, nil
+	}
+
+	if len(archived) == 0 {
+		if err := os.Remove(archiveFilename); err != nil {
+			return nil, fmt.Errorf("failed to remove archive file %s: %w", archiveFilename, err)
+		}
+		return missing, nil
+	}
+	if err := SaveToFile(archiveFilename, archived); err != nil {
+		return nil, fmt.Errorf("failed to write archive file %s: %w", archiveFilename, err)
+	}
+	return missing, nil
+}
+
+// ArchiveFiles returns the archive files in dir of any layout, sorted by name
+func ArchiveFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "todo_archive_*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	done := filepath.Join(dir, doneFilename)
+	if _, err := os.Stat(done); err == nil {
+		files = append(files, done)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// ArchivedItem is a todo in an archive file
+type ArchivedItem struct {
+	Item
+	File string // Path of the archive file holding the todo
+}
+
+// LoadArchives loads the todos of all archive files in dir, file by file in
+// name order and line by line within a file. Empty lines are skipped.
+func LoadArchives(dir string) ([]ArchivedItem, error) {
+	files, err := ArchiveFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var archived []ArchivedItem
+	for _, archiveFilename := range files {
+		items, err := LoadFromFile(archiveFilename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive file %s: %w", archiveFilename, err)
+		}
+		for _, item := range items {
+			if !item.IsEmpty() {
+				archived = append(archived, ArchivedItem{Item: item, File: archiveFilename})
+			}
+		}
+	}
+	return archived, nil
+}
+
+// Check returns an error if a todo is not in the archive files (any more),
+// before restoring todos changes any file
+func (a Archive) Check(items []Item) error {
+	archived, err := LoadArchives(a.Dir)
+	if err != nil {
+		return err
+	}
+
+	count := make(map[string]int, len(archived))
+	for _, item := range archived {
+		count[item.String()]++
+	}
+	for _, item := range items {
+		line := item.String()
+		if count[line] == 0 {
+			return fmt.Errorf("not in the archive: %s", line)
+		}
+		count[line]--
+	}
+	return nil
+}
+
+// RestoreArchived moves archived todos back to the end of filename as open
+// tasks and records the restore in the journal, so it can be undone.
+// filename is saved before the todos are taken out of the archive files, so
+// a failure in between never loses them.
+func RestoreArchived(filename string, archive Archive, items []Item) (JournalEntry, error) {
+	var restore JournalEntry
+	err := WithLock(filename, func() error {
+		var err error
+		restore, err = restoreLocked(filename, archive, items)
+		return err
+	})
+	return restore, err
+}
+
+// RestoreArchivedIDs is RestoreArchived for the archived todos with the
+// given numbers, counted from 1 in the order of LoadArchives. The numbers
+// are looked up while holding the lock, so an archive run at the same time
+// can't make them point at other todos.
+func RestoreArchivedIDs(filename string, archive Archive, ids []int) (JournalEntry, error) {
+	var restore JournalEntry
+	err := WithLock(filename, func() error {
+		archived, err := LoadArchives(archive.Dir)
+		if err != nil {
+			return err
+		}
+
+		var items []Item
+		seen := make(map[int]bool, len(ids))
+		for _, id := range ids {
+			if id < 1 || id > len(archived) {
+				return fmt.Errorf("no archived task %d (the archive has %d tasks)", id, len(archived))
+			}
+			if !seen[id] {
+				seen[id] = true
+				items = append(items, archived[id-1].Item)
+			}
+		}
+
+		restore, err = restoreLocked(filename, archive, items)
+		return err
+	})
+	return restore, err
+}
+
+// restoreLocked restores archived todos for RestoreArchived, the caller must
+// hold the lock
+func restoreLocked(filename string, archive Archive, items []Item) (JournalEntry, error) {
+	// Nothing changes if a task is not in the archive any more
+	if err := archive.Check(items); err != nil {
+		return JournalEntry{}, err
+	}
+
+	before, err := LoadFromFile(filename)
+	if err != nil {
+		return JournalEntry{}, err
+	}
+	todos := slices.Clone(before)
+	for _, item := range items {
+		todos = append(todos, Reopen(item))
+	}
+
+	if err := saveMoving(filename, before, todos, archive, nil, items); err != nil {
+		return JournalEntry{}, err
+	}
+
+	entry := NewJournalEntry(OpRestore, Lines(before), Lines(todos))
+	entry.Restored = Lines(items)
+	// The entry is returned even if the journal can't be written
+	written, err := AppendJournal(filename, entry)
+	if len(written) > 0 {
+		entry = written[0]
+	}
+	return entry, err
+}
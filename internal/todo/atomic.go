@@ -0,0 +1,66 @@
+package todo
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// writeFileAtomic replaces filename with the output of write
+// The data is written to a temporary file in the same directory, flushed to
+// disk and renamed over the original, so a crash or a failed write never
+// leaves a half-written file behind. The mode of an existing file is kept,
+// and symlinks are followed so the link itself stays in place.
+func writeFileAtomic(filename string, write func(w io.Writer) error) (err error) {
+	if target, evalErr := filepath.EvalSymlinks(filename); evalErr == nil {
+		filename = target
+	}
+
+	mode := os.FileMode(0644)
+	if info, statErr := os.Stat(filename); statErr == nil {
+		mode = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(filename)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	// Clean up the temporary file unless it was renamed into place
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()           // Best effort, may already be closed
+			_ = os.Remove(tmp.Name()) // Best effort cleanup on error path
+		}
+	}()
+
+	writer := bufio.NewWriter(tmp)
+	if err = write(writer); err != nil {
+		return err
+	}
+	if err = writer.Flush(); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(mode); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), filename); err != nil {
+		return err
+	}
+
+	// Persist the rename itself; not supported on every platform, so best effort
+	if d, openErr := os.Open(dir); openErr == nil {
+		_ = d.Sync()
+		_ = d.Close()
+	}
+
+	return nil
+}
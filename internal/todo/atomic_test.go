@@ -0,0 +1,126 @@
+package todo
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// assertNoTempFiles fails the test if temporary files were left in dir
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, ".*.tmp-*"))
+	if err != nil {
+		t.Fatalf("Glob() error = %v", err)
+	}
+	if len(matches) != 0 {
+		t.Errorf("Temporary files left behind: %v", matches)
+	}
+}
+
+func TestWriteFileAtomic_WriteFailureKeepsOriginal(t *testing.T) {
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "todo.txt")
+	original := "(A) Keep me\nAnd me\n"
+	if err := os.WriteFile(tmpFile, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	errDiskFull := errors.New("no space left on device")
+	err := writeFileAtomic(tmpFile, func(w io.Writer) error {
+		if _, err := io.WriteString(w, "half a "); err != nil {
+			return err
+		}
+		return errDiskFull
+	})
+	if !errors.Is(err, errDiskFull) {
+		t.Fatalf("writeFileAtomic() error = %v, want %v", err, errDiskFull)
+	}
+
+	content, err := os.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(content) != original {
+		t.Errorf("File content = %q, want original %q", content, original)
+	}
+	assertNoTempFiles(t, tmpDir)
+}
+
+func TestWriteFileAtomic_RenameFailureCleansUp(t *testing.T) {
+	tmpDir := t.TempDir()
+	// A non-empty directory cannot be replaced by a file
+	target := filepath.Join(tmpDir, "todo.txt")
+	if err := os.MkdirAll(filepath.Join(target, "child"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	err := writeFileAtomic(target, func(w io.Writer) error {
+		_, err := io.WriteString(w, "Task\n")
+		return err
+	})
+	if err == nil {
+		t.Fatal("writeFileAtomic() should fail when the target is a directory")
+	}
+	assertNoTempFiles(t, tmpDir)
+}
+
+func TestSaveToFile_PreservesMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not supported on Windows")
+	}
+
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "todo.txt")
+	if err := os.WriteFile(tmpFile, []byte("Old task\n"), 0600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	if err := SaveToFile(tmpFile, []Item{Parse("New task")}); err != nil {
+		t.Fatalf("SaveToFile() error = %v", err)
+	}
+
+	info, err := os.Stat(tmpFile)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("File mode = %v, want %v", info.Mode().Perm(), os.FileMode(0600))
+	}
+	assertNoTempFiles(t, tmpDir)
+}
+
+func TestSaveToFile_FollowsSymlink(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, "real_todo.txt")
+	link := filepath.Join(tmpDir, "todo.txt")
+	if err := os.WriteFile(target, []byte("Old task\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	if err := SaveToFile(link, []Item{Parse("New task")}); err != nil {
+		t.Fatalf("SaveToFile() error = %v", err)
+	}
+
+	info, err := os.Lstat(link)
+	if err != nil {
+		t.Fatalf("Lstat() error = %v", err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		t.Error("SaveToFile() replaced the symlink with a regular file")
+	}
+
+	content, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("Failed to read target: %v", err)
+	}
+	if string(content) != "New task\n" {
+		t.Errorf("Target content = %q, want %q", content, "New task\n")
+	}
+}
@@ -0,0 +1,50 @@
+package todo
+
+import "time"
+
+// dateTag returns the date stored in a key:value tag such as due:2025-10-01
+// The date is returned at midnight UTC, like other todo.txt dates.
+func (i Item) dateTag(key string) (time.Time, bool) {
+	value, ok := i.Tag(key)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// DueDate returns the date of the due: tag and whether the item has a valid one
+func (i Item) DueDate() (time.Time, bool) {
+	return i.dateTag("due")
+}
+
+// ThresholdDate returns the date of the t: tag and whether the item has a valid one
+func (i Item) ThresholdDate() (time.Time, bool) {
+	return i.dateTag("t")
+}
+
+// IsBeforeThreshold returns true if the item is not actionable yet because
+// its threshold date lies in the future. Completed items are never before
+// their threshold.
+func (i Item) IsBeforeThreshold() bool {
+	if i.Completed {
+		return false
+	}
+	threshold, ok := i.ThresholdDate()
+	if !ok {
+		return false
+	}
+	return DaysUntil(threshold, time.Now()) > 0
+}
+
+// DaysUntil returns the number of calendar days from now until date
+// It is negative for dates in the past and 0 for today.
+func DaysUntil(date, now time.Time) int {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	return int(day.Sub(today).Hours() / 24)
+}
@@ -0,0 +1,119 @@
+package todo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDueDate(t *testing.T) {
+	tests := []struct {
+		name        string
+		line        string
+		expectedOK  bool
+		expectedDue string
+	}{
+		{
+			name:        "valid due date",
+			line:        "Pay rent due:2025-10-01",
+			expectedOK:  true,
+			expectedDue: "2025-10-01",
+		},
+		{
+			name:       "no due date",
+			line:       "Pay rent",
+			expectedOK: false,
+		},
+		{
+			name:       "invalid due date",
+			line:       "Pay rent due:tomorrow",
+			expectedOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			due, ok := Parse(tt.line).DueDate()
+			if ok != tt.expectedOK {
+				t.Fatalf("DueDate() ok = %v, want %v", ok, tt.expectedOK)
+			}
+			if ok && due.Format("2006-01-02") != tt.expectedDue {
+				t.Errorf("DueDate() = %s, want %s", due.Format("2006-01-02"), tt.expectedDue)
+			}
+		})
+	}
+}
+
+func TestDaysUntil(t *testing.T) {
+	now := time.Date(2025, 10, 15, 23, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		date     string
+		expected int
+	}{
+		{name: "today", date: "2025-10-15", expected: 0},
+		{name: "tomorrow", date: "2025-10-16", expected: 1},
+		{name: "yesterday", date: "2025-10-14", expected: -1},
+		{name: "next month", date: "2025-11-15", expected: 31},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			date, _ := time.Parse("2006-01-02", tt.date)
+			if got := DaysUntil(date, now); got != tt.expected {
+				t.Errorf("DaysUntil(%s) = %d, want %d", tt.date, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsBeforeThreshold(t *testing.T) {
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	today := time.Now().Format("2006-01-02")
+	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+
+	tests := []struct {
+		name     string
+		line     string
+		expected bool
+	}{
+		{
+			name:     "no threshold",
+			line:     "Plan trip",
+			expected: false,
+		},
+		{
+			name:     "threshold in the future",
+			line:     "Plan trip t:" + tomorrow,
+			expected: true,
+		},
+		{
+			name:     "threshold today is actionable",
+			line:     "Plan trip t:" + today,
+			expected: false,
+		},
+		{
+			name:     "threshold in the past",
+			line:     "Plan trip t:" + yesterday,
+			expected: false,
+		},
+		{
+			name:     "invalid threshold",
+			line:     "Plan trip t:someday",
+			expected: false,
+		},
+		{
+			name:     "completed item with future threshold",
+			line:     "x " + today + " Plan trip t:" + tomorrow,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.line).IsBeforeThreshold(); got != tt.expected {
+				t.Errorf("IsBeforeThreshold() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
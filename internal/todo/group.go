@@ -0,0 +1,154 @@
+package todo
+
+import (
+	"sort"
+	"time"
+)
+
+// IndexedItem wraps a todo item with its index in the main todos slice
+type IndexedItem struct {
+	Item  Item
+	Index int
+}
+
+// Group is a list of todos that share a context, project, priority or date
+type Group struct {
+	Name  string // Context, project, priority or date the todos share, or "No Context" and the like
+	Title string // Header to display, like "@Work" or "Due This Week"
+	Todos []IndexedItem
+	order string // Sort key of the group
+}
+
+// ViewOptions controls which todos GroupTodos includes
+type ViewOptions struct {
+	ShowFuture       bool            // Include tasks whose threshold date lies in the future
+	ShowOldCompleted bool            // Include completed tasks that are old enough to be archived
+	ArchiveAge       *int            // Days completed tasks stay visible, DefaultArchiveAge if nil
+	Filter           func(Item) bool // Only include tasks for which Filter returns true (if set)
+}
+
+// IsVisible returns true if the item passes the view options
+func (o ViewOptions) IsVisible(item Item) bool {
+	// Blank lines hold no task
+	if item.IsEmpty() {
+		return false
+	}
+
+	// Skip completed todos that are old enough to be archived
+	age := DefaultArchiveAge
+	if o.ArchiveAge != nil {
+		age = *o.ArchiveAge
+	}
+	if !o.ShowOldCompleted && !item.IsVisibleFor(age) {
+		return false
+	}
+
+	// Skip tasks that are not actionable yet
+	if !o.ShowFuture && item.IsBeforeThreshold() {
+		return false
+	}
+
+	return o.Filter == nil || o.Filter(item)
+}
+
+// PriorityValue returns a sort value for priority (lower is higher priority)
+func PriorityValue(priority string) int {
+	if priority == "" {
+		return 1000 // Unprioritized items come last
+	}
+	// (A) = 0, (B) = 1, ..., (Z) = 25
+	return int(priority[0] - 'A')
+}
+
+// dueBefore reports whether a is due before b
+// Items without a due date are considered due after items with one
+func dueBefore(a, b Item) bool {
+	aDue, aOK := a.DueDate()
+	if !aOK {
+		return false
+	}
+	bDue, bOK := b.DueDate()
+	if !bOK {
+		return true
+	}
+	return aDue.Before(bDue)
+}
+
+// SortByPriority sorts todos by completion status first (uncompleted before completed),
+// then by priority within each group (A is highest, unprioritized is lowest),
+// and finally by due date (earliest first, no due date last)
+func SortByPriority(todos []IndexedItem) {
+	// Simple bubble sort by completion status, then priority
+	for i := 0; i < len(todos); i++ {
+		for j := i + 1; j < len(todos); j++ {
+			// First compare completion status
+			iCompleted := todos[i].Item.Completed
+			jCompleted := todos[j].Item.Completed
+
+			// If completion status differs, uncompleted tasks come first
+			if iCompleted != jCompleted {
+				if iCompleted && !jCompleted {
+					todos[i], todos[j] = todos[j], todos[i]
+				}
+				continue
+			}
+
+			// If completion status is the same, sort by priority
+			iPriority := PriorityValue(todos[i].Item.Priority)
+			jPriority := PriorityValue(todos[j].Item.Priority)
+			if iPriority > jPriority {
+				todos[i], todos[j] = todos[j], todos[i]
+				continue
+			}
+
+			// If priority is the same too, sort by due date
+			if iPriority == jPriority && dueBefore(todos[j].Item, todos[i].Item) {
+				todos[i], todos[j] = todos[j], todos[i]
+			}
+		}
+	}
+}
+
+// GroupTodos groups todos as given by grouping, sorting the todos within
+// each group by priority
+func GroupTodos(todos []Item, grouping Grouping, opts ViewOptions) []Group {
+	return groupTodos(todos, grouping, opts, time.Now())
+}
+
+// groupTodos is GroupTodos with due weeks relative to now
+func groupTodos(todos []Item, grouping Grouping, opts ViewOptions, now time.Time) []Group {
+	keyFunc := groupKeyFuncs[grouping]
+	if keyFunc == nil {
+		keyFunc = groupKeyFuncs[GroupContext]
+	}
+
+	groupMap := make(map[string]*Group)
+	for i, item := range todos {
+		if !opts.IsVisible(item) {
+			continue
+		}
+
+		// A todo can be in several groups, like one per context
+		todoWithIdx := IndexedItem{Item: item, Index: i}
+		for _, key := range keyFunc(item, now) {
+			group, ok := groupMap[key.name]
+			if !ok {
+				group = &Group{Name: key.name, Title: key.title, order: key.order}
+				groupMap[key.name] = group
+			}
+			group.Todos = append(group.Todos, todoWithIdx)
+		}
+	}
+
+	groups := make([]Group, 0, len(groupMap))
+	for _, group := range groupMap {
+		// Sort todos within each group by priority
+		SortByPriority(group.Todos)
+		groups = append(groups, *group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].order < groups[j].order
+	})
+
+	return groups
+}
@@ -0,0 +1,519 @@
+package todo
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPriorityValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		priority string
+		expected int
+	}{
+		{
+			name:     "priority A should have value 0",
+			priority: "A",
+			expected: 0,
+		},
+		{
+			name:     "priority B should have value 1",
+			priority: "B",
+			expected: 1,
+		},
+		{
+			name:     "priority C should have value 2",
+			priority: "C",
+			expected: 2,
+		},
+		{
+			name:     "priority Z should have value 25",
+			priority: "Z",
+			expected: 25,
+		},
+		{
+			name:     "empty priority should have value 1000",
+			priority: "",
+			expected: 1000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := PriorityValue(tt.priority)
+			if result != tt.expected {
+				t.Errorf("PriorityValue(%q) = %d, want %d", tt.priority, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSortByPriority(t *testing.T) {
+	tests := []struct {
+		name     string
+		todos    []IndexedItem
+		expected []string // Expected order of priorities
+	}{
+		{
+			name: "sort A, C, B should result in A, B, C",
+			todos: []IndexedItem{
+				{Item: Item{Priority: "A", Description: "Task A"}},
+				{Item: Item{Priority: "C", Description: "Task C"}},
+				{Item: Item{Priority: "B", Description: "Task B"}},
+			},
+			expected: []string{"A", "B", "C"},
+		},
+		{
+			name: "sort with empty priority last",
+			todos: []IndexedItem{
+				{Item: Item{Priority: "", Description: "No priority"}},
+				{Item: Item{Priority: "B", Description: "Task B"}},
+				{Item: Item{Priority: "A", Description: "Task A"}},
+			},
+			expected: []string{"A", "B", ""},
+		},
+		{
+			name: "already sorted list remains sorted",
+			todos: []IndexedItem{
+				{Item: Item{Priority: "A", Description: "Task A"}},
+				{Item: Item{Priority: "B", Description: "Task B"}},
+				{Item: Item{Priority: "C", Description: "Task C"}},
+			},
+			expected: []string{"A", "B", "C"},
+		},
+		{
+			name: "reverse sorted list gets sorted correctly",
+			todos: []IndexedItem{
+				{Item: Item{Priority: "Z", Description: "Task Z"}},
+				{Item: Item{Priority: "B", Description: "Task B"}},
+				{Item: Item{Priority: "A", Description: "Task A"}},
+			},
+			expected: []string{"A", "B", "Z"},
+		},
+		{
+			name: "single item remains unchanged",
+			todos: []IndexedItem{
+				{Item: Item{Priority: "A", Description: "Task A"}},
+			},
+			expected: []string{"A"},
+		},
+		{
+			name:     "empty list remains empty",
+			todos:    []IndexedItem{},
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SortByPriority(tt.todos)
+
+			if len(tt.todos) != len(tt.expected) {
+				t.Fatalf("Expected %d todos, got %d", len(tt.expected), len(tt.todos))
+			}
+
+			for i, expectedPriority := range tt.expected {
+				if tt.todos[i].Item.Priority != expectedPriority {
+					t.Errorf("todos[%d].Priority = %q, want %q", i, tt.todos[i].Item.Priority, expectedPriority)
+				}
+			}
+		})
+	}
+}
+
+func TestGroupTodos_Context(t *testing.T) {
+	tests := []struct {
+		name             string
+		todos            []Item
+		expectedContexts []string // Expected context names in order
+		expectedCounts   map[string]int
+	}{
+		{
+			name: "single context",
+			todos: []Item{
+				{Description: "Task 1 @Work", Contexts: []string{"Work"}},
+				{Description: "Task 2 @Work", Contexts: []string{"Work"}},
+			},
+			expectedContexts: []string{"Work"},
+			expectedCounts: map[string]int{
+				"Work": 2,
+			},
+		},
+		{
+			name: "multiple contexts",
+			todos: []Item{
+				{Description: "Task 1 @Work", Contexts: []string{"Work"}},
+				{Description: "Task 2 @Personal", Contexts: []string{"Personal"}},
+				{Description: "Task 3 @Work", Contexts: []string{"Work"}},
+			},
+			expectedContexts: []string{"Personal", "Work"},
+			expectedCounts: map[string]int{
+				"Work":     2,
+				"Personal": 1,
+			},
+		},
+		{
+			name: "no context items go to No Context",
+			todos: []Item{
+				{Description: "Task without context", Contexts: []string{}},
+				{Description: "Another task", Contexts: []string{}},
+			},
+			expectedContexts: []string{"No Context"},
+			expectedCounts: map[string]int{
+				"No Context": 2,
+			},
+		},
+		{
+			name: "mixed context and no context",
+			todos: []Item{
+				{Description: "Task @Work", Contexts: []string{"Work"}},
+				{Description: "Task without context", Contexts: []string{}},
+			},
+			expectedContexts: []string{"No Context", "Work"},
+			expectedCounts: map[string]int{
+				"No Context": 1,
+				"Work":       1,
+			},
+		},
+		{
+			name: "todo with multiple contexts appears in both",
+			todos: []Item{
+				{Description: "Task @Work @Office", Contexts: []string{"Work", "Office"}},
+			},
+			expectedContexts: []string{"Office", "Work"},
+			expectedCounts: map[string]int{
+				"Office": 1,
+				"Work":   1,
+			},
+		},
+		{
+			name: "contexts sorted alphabetically",
+			todos: []Item{
+				{Description: "Task @Zebra", Contexts: []string{"Zebra"}},
+				{Description: "Task @Apple", Contexts: []string{"Apple"}},
+				{Description: "Task @Mango", Contexts: []string{"Mango"}},
+			},
+			expectedContexts: []string{"Apple", "Mango", "Zebra"},
+			expectedCounts: map[string]int{
+				"Zebra": 1,
+				"Apple": 1,
+				"Mango": 1,
+			},
+		},
+		{
+			name: "old completed items are filtered out",
+			todos: []Item{
+				{
+					Description:    "Old completed @Work",
+					Contexts:       []string{"Work"},
+					Completed:      true,
+					CompletionDate: time.Now().AddDate(0, 0, -10).Format("2006-01-02"),
+				},
+				{Description: "Active task @Work", Contexts: []string{"Work"}},
+			},
+			expectedContexts: []string{"Work"},
+			expectedCounts: map[string]int{
+				"Work": 1,
+			},
+		},
+		{
+			name:             "empty todo list",
+			todos:            []Item{},
+			expectedContexts: []string{},
+			expectedCounts:   map[string]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := GroupTodos(tt.todos, GroupContext, ViewOptions{})
+
+			// Check number of context groups
+			if len(result) != len(tt.expectedContexts) {
+				t.Errorf("Expected %d context groups, got %d", len(tt.expectedContexts), len(result))
+			}
+
+			// Check context names and order
+			for i, expectedContext := range tt.expectedContexts {
+				if i >= len(result) {
+					t.Errorf("Missing context at index %d: expected %q", i, expectedContext)
+					continue
+				}
+				if result[i].Name != expectedContext {
+					t.Errorf("Context[%d] = %q, want %q", i, result[i].Name, expectedContext)
+				}
+			}
+
+			// Check counts per context
+			for _, contextList := range result {
+				expectedCount, exists := tt.expectedCounts[contextList.Name]
+				if !exists {
+					t.Errorf("Unexpected context: %q", contextList.Name)
+					continue
+				}
+				if len(contextList.Todos) != expectedCount {
+					t.Errorf("Context %q has %d todos, want %d", contextList.Name, len(contextList.Todos), expectedCount)
+				}
+			}
+		})
+	}
+}
+
+func TestGroupTodos_PrioritySorting(t *testing.T) {
+	todos := []Item{
+		{Description: "Task C @Work", Priority: "C", Contexts: []string{"Work"}},
+		{Description: "Task A @Work", Priority: "A", Contexts: []string{"Work"}},
+		{Description: "Task B @Work", Priority: "B", Contexts: []string{"Work"}},
+	}
+
+	result := GroupTodos(todos, GroupContext, ViewOptions{})
+
+	if len(result) != 1 {
+		t.Fatalf("Expected 1 context group, got %d", len(result))
+	}
+
+	workContext := result[0]
+	if workContext.Name != "Work" {
+		t.Fatalf("Expected Work context, got %q", workContext.Name)
+	}
+
+	// Verify todos are sorted by priority
+	expectedPriorities := []string{"A", "B", "C"}
+	if len(workContext.Todos) != len(expectedPriorities) {
+		t.Fatalf("Expected %d todos, got %d", len(expectedPriorities), len(workContext.Todos))
+	}
+
+	for i, expectedPriority := range expectedPriorities {
+		if workContext.Todos[i].Item.Priority != expectedPriority {
+			t.Errorf("Todo[%d] priority = %q, want %q", i, workContext.Todos[i].Item.Priority, expectedPriority)
+		}
+	}
+}
+
+func TestSortByPriority_CompletionStatus(t *testing.T) {
+	tests := []struct {
+		name              string
+		todos             []IndexedItem
+		expectedCompleted []bool   // Expected completion status in order
+		expectedPriority  []string // Expected priority in order
+	}{
+		{
+			name: "uncompleted tasks come before completed tasks",
+			todos: []IndexedItem{
+				{Item: Item{Priority: "A", Description: "Completed A", Completed: true}},
+				{Item: Item{Priority: "B", Description: "Uncompleted B", Completed: false}},
+				{Item: Item{Priority: "C", Description: "Uncompleted C", Completed: false}},
+			},
+			expectedCompleted: []bool{false, false, true},
+			expectedPriority:  []string{"B", "C", "A"},
+		},
+		{
+			name: "completed tasks sorted by priority within their group",
+			todos: []IndexedItem{
+				{Item: Item{Priority: "C", Description: "Completed C", Completed: true}},
+				{Item: Item{Priority: "A", Description: "Completed A", Completed: true}},
+				{Item: Item{Priority: "B", Description: "Completed B", Completed: true}},
+			},
+			expectedCompleted: []bool{true, true, true},
+			expectedPriority:  []string{"A", "B", "C"},
+		},
+		{
+			name: "uncompleted tasks sorted by priority within their group",
+			todos: []IndexedItem{
+				{Item: Item{Priority: "C", Description: "Uncompleted C", Completed: false}},
+				{Item: Item{Priority: "A", Description: "Uncompleted A", Completed: false}},
+				{Item: Item{Priority: "B", Description: "Uncompleted B", Completed: false}},
+			},
+			expectedCompleted: []bool{false, false, false},
+			expectedPriority:  []string{"A", "B", "C"},
+		},
+		{
+			name: "mixed priorities with mixed completion status",
+			todos: []IndexedItem{
+				{Item: Item{Priority: "C", Description: "Completed C", Completed: true}},
+				{Item: Item{Priority: "A", Description: "Uncompleted A", Completed: false}},
+				{Item: Item{Priority: "B", Description: "Completed B", Completed: true}},
+				{Item: Item{Priority: "", Description: "Uncompleted no priority", Completed: false}},
+				{Item: Item{Priority: "", Description: "Completed no priority", Completed: true}},
+			},
+			expectedCompleted: []bool{false, false, true, true, true},
+			expectedPriority:  []string{"A", "", "B", "C", ""},
+		},
+		{
+			name: "completed with higher priority still comes after uncompleted with lower priority",
+			todos: []IndexedItem{
+				{Item: Item{Priority: "A", Description: "Completed A", Completed: true}},
+				{Item: Item{Priority: "Z", Description: "Uncompleted Z", Completed: false}},
+			},
+			expectedCompleted: []bool{false, true},
+			expectedPriority:  []string{"Z", "A"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SortByPriority(tt.todos)
+
+			if len(tt.todos) != len(tt.expectedCompleted) {
+				t.Fatalf("Expected %d todos, got %d", len(tt.expectedCompleted), len(tt.todos))
+			}
+
+			for i := range tt.todos {
+				if tt.todos[i].Item.Completed != tt.expectedCompleted[i] {
+					t.Errorf("todos[%d].Completed = %v, want %v (description: %s)",
+						i, tt.todos[i].Item.Completed, tt.expectedCompleted[i], tt.todos[i].Item.Description)
+				}
+				if tt.todos[i].Item.Priority != tt.expectedPriority[i] {
+					t.Errorf("todos[%d].Priority = %q, want %q (description: %s)",
+						i, tt.todos[i].Item.Priority, tt.expectedPriority[i], tt.todos[i].Item.Description)
+				}
+			}
+		})
+	}
+}
+
+func TestSortByPriority_DueDateTieBreaker(t *testing.T) {
+	todos := []IndexedItem{
+		{Item: Parse("(A) No due date")},
+		{Item: Parse("(B) Due later due:2025-10-20")},
+		{Item: Parse("(A) Due later due:2025-10-20")},
+		{Item: Parse("(A) Due sooner due:2025-10-10")},
+		{Item: Parse("Unprioritized due:2025-10-01")},
+	}
+
+	SortByPriority(todos)
+
+	expected := []string{
+		"Due sooner due:2025-10-10",
+		"Due later due:2025-10-20",
+		"No due date",
+		"Due later due:2025-10-20",
+		"Unprioritized due:2025-10-01",
+	}
+	for i, description := range expected {
+		if todos[i].Item.Description != description {
+			t.Errorf("todos[%d] = %q, want %q", i, todos[i].Item.Description, description)
+		}
+	}
+}
+
+func TestGroupTodos_FutureThreshold(t *testing.T) {
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	todos := []Item{
+		Parse("Actionable task @Work"),
+		Parse("Start next month @Work t:" + tomorrow),
+		Parse("Only future @Later t:" + tomorrow),
+	}
+
+	hidden := GroupTodos(todos, GroupContext, ViewOptions{})
+	if len(hidden) != 1 || len(hidden[0].Todos) != 1 {
+		t.Fatalf("Expected only the actionable task to be visible, got %+v", hidden)
+	}
+	if hidden[0].Todos[0].Index != 0 {
+		t.Errorf("Visible todo index = %d, want 0", hidden[0].Todos[0].Index)
+	}
+
+	shown := GroupTodos(todos, GroupContext, ViewOptions{ShowFuture: true})
+	if len(shown) != 2 {
+		t.Fatalf("Expected 2 context groups when showing future tasks, got %d", len(shown))
+	}
+}
+
+func TestViewOptions_IsVisible(t *testing.T) {
+	oldDate := time.Now().AddDate(0, 0, -10).Format("2006-01-02")
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+
+	oldCompleted := Parse("x " + oldDate + " Old task")
+	doneToday := Parse("x " + time.Now().Format("2006-01-02") + " Done today")
+	zero, month := 0, 30
+	future := Parse("Future task t:" + tomorrow)
+	work := Parse("Work task @Work")
+	onlyWork := func(item Item) bool {
+		for _, context := range item.Contexts {
+			if context == "Work" {
+				return true
+			}
+		}
+		return false
+	}
+
+	tests := []struct {
+		name     string
+		opts     ViewOptions
+		item     Item
+		expected bool
+	}{
+		{name: "old completed hidden by default", opts: ViewOptions{}, item: oldCompleted, expected: false},
+		{name: "old completed shown on request", opts: ViewOptions{ShowOldCompleted: true}, item: oldCompleted, expected: true},
+		{name: "old completed shown with longer archive age", opts: ViewOptions{ArchiveAge: &month}, item: oldCompleted, expected: true},
+		{name: "done today shown by default", opts: ViewOptions{}, item: doneToday, expected: true},
+		{name: "done today hidden with archive age 0", opts: ViewOptions{ArchiveAge: &zero}, item: doneToday, expected: false},
+		{name: "future hidden by default", opts: ViewOptions{}, item: future, expected: false},
+		{name: "future shown on request", opts: ViewOptions{ShowFuture: true}, item: future, expected: true},
+		{name: "filter match", opts: ViewOptions{Filter: onlyWork}, item: work, expected: true},
+		{name: "filter mismatch", opts: ViewOptions{Filter: onlyWork}, item: future, expected: false},
+		{name: "blank line hidden", opts: ViewOptions{ShowFuture: true, ShowOldCompleted: true}, item: Parse("   "), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.IsVisible(tt.item); got != tt.expected {
+				t.Errorf("IsVisible() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGroupTodos(t *testing.T) {
+	now := time.Date(2025, 10, 15, 9, 0, 0, 0, time.Local) // Wednesday
+	todos := []Item{
+		Parse("(B) 2025-09-03 Report @Work +Q4 due:2025-10-14"),
+		Parse("(A) 2025-10-01 Slides @Work +Q4 +Talk due:2025-10-17"),
+		Parse("Call mom @Home due:2025-10-21"),
+		Parse("2025-10-12 Plan trip due:2025-11-05"),
+		Parse("Read book"),
+	}
+
+	titles := func(groups []Group) []string {
+		var result []string
+		for _, group := range groups {
+			result = append(result, fmt.Sprintf("%s:%d", group.Title, len(group.Todos)))
+		}
+		return result
+	}
+
+	tests := []struct {
+		grouping Grouping
+		expected []string
+	}{
+		{GroupContext, []string{"No Context:2", "@Home:1", "@Work:2"}},
+		{GroupProject, []string{"No Project:3", "+Q4:2", "+Talk:1"}},
+		{GroupPriority, []string{"Priority A:1", "Priority B:1", "No Priority:3"}},
+		{GroupDue, []string{"Overdue:1", "Due This Week:1", "Due Next Week:1", "Due Week of Nov 03:1", "No Due Date:1"}},
+		{GroupCreated, []string{"Created October 2025:2", "Created September 2025:1", "No Creation Date:2"}},
+		{GroupNone, []string{"All Tasks:5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.grouping), func(t *testing.T) {
+			got := titles(groupTodos(todos, tt.grouping, ViewOptions{}, now))
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("groupTodos(%s) = %v, want %v", tt.grouping, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseGrouping(t *testing.T) {
+	if grouping, err := ParseGrouping(""); err != nil || grouping != GroupContext {
+		t.Errorf("ParseGrouping(\"\") = %q, %v, want %q", grouping, err, GroupContext)
+	}
+	if grouping, err := ParseGrouping("Due"); err != nil || grouping != GroupDue {
+		t.Errorf("ParseGrouping(\"Due\") = %q, %v, want %q", grouping, err, GroupDue)
+	}
+	if _, err := ParseGrouping("color"); err == nil {
+		t.Error("ParseGrouping(\"color\") should fail")
+	}
+}
@@ -0,0 +1,119 @@
+package todo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Grouping decides how todos are split into groups
+type Grouping string
+
+// Available groupings
+const (
+	GroupContext  Grouping = "context"  // One group per context
+	GroupProject  Grouping = "project"  // One group per project
+	GroupPriority Grouping = "priority" // One group per priority
+	GroupDue      Grouping = "due"      // Overdue, then one group per week of the due date
+	GroupCreated  Grouping = "created"  // One group per month of the creation date, newest first
+	GroupNone     Grouping = "none"     // A single group with all todos
+)
+
+// Groupings lists all groupings in the order they are offered to users
+var Groupings = []Grouping{GroupContext, GroupProject, GroupPriority, GroupDue, GroupCreated, GroupNone}
+
+// ParseGrouping parses the name of a grouping, an empty name is GroupContext
+func ParseGrouping(name string) (Grouping, error) {
+	if name == "" {
+		return GroupContext, nil
+	}
+	for _, grouping := range Groupings {
+		if strings.EqualFold(name, string(grouping)) {
+			return grouping, nil
+		}
+	}
+
+	names := make([]string, len(Groupings))
+	for i, grouping := range Groupings {
+		names[i] = string(grouping)
+	}
+	return "", fmt.Errorf("unknown grouping %q, use one of: %s", name, strings.Join(names, ", "))
+}
+
+// groupKey identifies the group a todo belongs to
+type groupKey struct {
+	name  string
+	title string
+	order string
+}
+
+// groupKeyFuncs return the groups a todo belongs to for each grouping
+var groupKeyFuncs = map[Grouping]func(item Item, now time.Time) []groupKey{
+	GroupContext: func(item Item, _ time.Time) []groupKey {
+		// Todos without a context come first
+		if len(item.Contexts) == 0 {
+			return []groupKey{{name: "No Context", title: "No Context", order: "0"}}
+		}
+		keys := make([]groupKey, len(item.Contexts))
+		for i, context := range item.Contexts {
+			keys[i] = groupKey{name: context, title: "@" + context, order: "1" + context}
+		}
+		return keys
+	},
+	GroupProject: func(item Item, _ time.Time) []groupKey {
+		// Todos without a project come first, like with contexts
+		if len(item.Projects) == 0 {
+			return []groupKey{{name: "No Project", title: "No Project", order: "0"}}
+		}
+		keys := make([]groupKey, len(item.Projects))
+		for i, project := range item.Projects {
+			keys[i] = groupKey{name: project, title: "+" + project, order: "1" + project}
+		}
+		return keys
+	},
+	GroupPriority: func(item Item, _ time.Time) []groupKey {
+		if item.Priority == "" {
+			return []groupKey{{name: "No Priority", title: "No Priority", order: "1"}}
+		}
+		return []groupKey{{name: item.Priority, title: "Priority " + item.Priority, order: "0" + item.Priority}}
+	},
+	GroupDue: func(item Item, now time.Time) []groupKey {
+		due, ok := item.DueDate()
+		if !ok {
+			return []groupKey{{name: "No Due Date", title: "No Due Date", order: "9"}}
+		}
+		if DaysUntil(due, now) < 0 && !item.Completed {
+			return []groupKey{{name: "Overdue", title: "Overdue", order: "0"}}
+		}
+
+		week := startOfWeek(due)
+		thisWeek := startOfWeek(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC))
+		name := week.Format("2006-01-02")
+		title := "Due Week of " + week.Format("Jan 02")
+		switch DaysUntil(week, thisWeek) {
+		case 0:
+			title = "Due This Week"
+		case 7:
+			title = "Due Next Week"
+		}
+		return []groupKey{{name: name, title: title, order: "1" + name}}
+	},
+	GroupCreated: func(item Item, _ time.Time) []groupKey {
+		created, ok := parseDate(item.CreationDate)
+		if !ok {
+			return []groupKey{{name: "No Creation Date", title: "No Creation Date", order: "9"}}
+		}
+		// Newest months first
+		order := fmt.Sprintf("1%04d%02d", 9999-created.Year(), 12-int(created.Month()))
+		return []groupKey{{name: created.Format("2006-01"), title: "Created " + created.Format("January 2006"), order: order}}
+	},
+	GroupNone: func(Item, time.Time) []groupKey {
+		return []groupKey{{name: "All", title: "All Tasks", order: "0"}}
+	},
+}
+
+// startOfWeek returns the Monday of the week date is in
+func startOfWeek(date time.Time) time.Time {
+	offset := (int(date.Weekday()) + 6) % 7 // Days since Monday
+	return date.AddDate(0, 0, -offset)
+}
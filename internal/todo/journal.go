@@ -0,0 +1,424 @@
+package todo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Operations recorded in the journal
+const (
+	OpAdd      = "add"
+	OpEdit     = "edit"
+	OpComplete = "complete"
+	OpDelete   = "delete"
+	OpPriority = "priority"
+	OpArchive  = "archive"
+	OpRestore  = "restore"
+	OpUndo     = "undo"
+	OpRedo     = "redo"
+	OpRevert   = "revert"
+)
+
+// ErrNoSuchEntry is returned when a journal entry does not exist
+var ErrNoSuchEntry = errors.New("no such journal entry")
+
+// ErrJournal is wrapped by the errors of AppendJournal. The journal is
+// written after todo.txt was saved, so the change itself is done: callers
+// should report it as a warning rather than a failed save.
+var ErrJournal = errors.New("saved, but not recorded in the journal")
+
+// LineChange is a single line of todo.txt that was added, removed or changed
+// An empty Before means the line was added, an empty After that it was removed.
+type LineChange struct {
+	Line   int    `json:"line"` // 1-based line number before the change, after it for added lines
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// JournalEntry records one operation on todo.txt
+type JournalEntry struct {
+	ID       int          `json:"id"`
+	Time     time.Time    `json:"time"`
+	Op       string       `json:"op"`
+	Changes  []LineChange `json:"changes"`
+	Archived []string     `json:"archived,omitempty"` // Lines moved into archive files
+	Restored []string     `json:"restored,omitempty"` // Lines moved out of archive files
+	Reverts  int          `json:"reverts,omitempty"`  // ID of the entry undone by a revert
+}
+
+// IsEmpty returns true if the entry changed nothing
+func (e JournalEntry) IsEmpty() bool {
+	return len(e.Changes) == 0 && len(e.Archived) == 0 && len(e.Restored) == 0
+}
+
+// JournalPath returns the path of the journal kept next to a todo file,
+// todo_journal.jsonl for todo.txt
+func JournalPath(filename string) string {
+	base := filepath.Base(filename)
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	return filepath.Join(filepath.Dir(filename), base+"_journal.jsonl")
+}
+
+// Lines returns the todos as todo.txt lines
+func Lines(todos []Item) []string {
+	lines := make([]string, len(todos))
+	for i, item := range todos {
+		lines[i] = item.String()
+	}
+	return lines
+}
+
+// NewJournalEntry describes the change from before to after as a journal entry
+func NewJournalEntry(op string, before, after []string) JournalEntry {
+	return JournalEntry{Op: op, Changes: Diff(before, after)}
+}
+
+// LoadJournal reads all entries of the journal for filename, oldest first
+// A missing journal has no entries.
+func LoadJournal(filename string) ([]JournalEntry, error) {
+	file, err := os.Open(JournalPath(filename))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close() //nolint:errcheck // Read-only operation, close error not critical
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 16*1024*1024) // Archive entries can hold many lines
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid journal entry %q: %w", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// AppendJournal adds entries to the journal for filename and returns them
+// as written. Entries get the next free IDs and, if unset, the current time.
+// Empty entries are skipped. The caller should hold the lock for filename.
+func AppendJournal(filename string, entries ...JournalEntry) ([]JournalEntry, error) {
+	written, err := appendJournal(filename, entries)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrJournal, err)
+	}
+	return written, nil
+}
+
+func appendJournal(filename string, entries []JournalEntry) ([]JournalEntry, error) {
+	lastID, err := lastJournalID(JournalPath(filename))
+	if err != nil {
+		return nil, err
+	}
+	nextID := lastID + 1
+
+	file, err := os.OpenFile(JournalPath(filename), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+
+	var written []JournalEntry
+	encoder := json.NewEncoder(file)
+	for _, entry := range entries {
+		if entry.IsEmpty() {
+			continue
+		}
+		entry.ID = nextID
+		nextID++
+		if entry.Time.IsZero() {
+			entry.Time = time.Now()
+		}
+		if err := encoder.Encode(entry); err != nil {
+			_ = file.Close() // Best effort close on error path
+			return nil, fmt.Errorf("failed to write journal: %w", err)
+		}
+		written = append(written, entry)
+	}
+
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close journal: %w", err)
+	}
+	return written, nil
+}
+
+// lastJournalID returns the ID of the last entry in the journal at path, 0
+// if it has none. Only the end of the file is read, the journal keeps growing.
+func lastJournalID(path string) (int, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close() //nolint:errcheck // Read-only operation, close error not critical
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	// Read backwards in chunks until the start of the last line is found
+	const chunkSize = 4096
+	var tail []byte
+	for offset := info.Size(); offset > 0; {
+		size := min(chunkSize, offset)
+		offset -= size
+		chunk := make([]byte, size)
+		if _, err := file.ReadAt(chunk, offset); err != nil {
+			return 0, fmt.Errorf("failed to read journal: %w", err)
+		}
+		tail = append(chunk, tail...)
+
+		trimmed := bytes.TrimRight(tail, " \t\r\n")
+		start := bytes.LastIndexByte(trimmed, '\n')
+		if start == -1 && offset > 0 {
+			continue
+		}
+		line := trimmed[start+1:]
+		if len(line) == 0 {
+			return 0, nil
+		}
+		var entry JournalEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return 0, fmt.Errorf("invalid journal entry %q: %w", line, err)
+		}
+		return entry.ID, nil
+	}
+	return 0, nil
+}
+
+// Diff returns the lines that changed from before to after
+// Removed and added lines next to each other are paired up as changed lines.
+// Blank lines are ignored.
+func Diff(before, after []string) []LineChange {
+	// Skip the common prefix and suffix, most operations touch a few lines
+	start := 0
+	for start < len(before) && start < len(after) && before[start] == after[start] {
+		start++
+	}
+	endBefore, endAfter := len(before), len(after)
+	for endBefore > start && endAfter > start && before[endBefore-1] == after[endAfter-1] {
+		endBefore--
+		endAfter--
+	}
+	a := before[start:endBefore]
+	b := after[start:endAfter]
+
+	// Longest common subsequence of the remaining lines
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var changes []LineChange
+	var removed, added []LineChange
+	flush := func() {
+		// Pair removed and added lines of a hunk as changed lines
+		for len(removed) > 0 && len(added) > 0 {
+			changes = append(changes, LineChange{Line: removed[0].Line, Before: removed[0].Before, After: added[0].After})
+			removed, added = removed[1:], added[1:]
+		}
+		changes = append(changes, removed...)
+		changes = append(changes, added...)
+		removed, added = nil, nil
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			flush()
+			i++
+			j++
+		case j >= len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			if strings.TrimSpace(a[i]) != "" {
+				removed = append(removed, LineChange{Line: start + i + 1, Before: a[i]})
+			}
+			i++
+		default:
+			if strings.TrimSpace(b[j]) != "" {
+				added = append(added, LineChange{Line: start + j + 1, After: b[j]})
+			}
+			j++
+		}
+	}
+	flush()
+
+	return changes
+}
+
+// Revert undoes the changes of the entry on lines
+// Lines are matched by content, so later operations on other lines don't get
+// in the way. Returns an error if a line the entry produced is gone.
+func (e JournalEntry) Revert(lines []string) ([]string, error) {
+	result := append([]string(nil), lines...)
+
+	// Changed and added lines are found by their content
+	offset := 0
+	for _, change := range e.Changes {
+		if change.After == "" {
+			continue
+		}
+		idx := findLine(result, change.After, change.Line-1)
+		if idx == -1 {
+			return nil, fmt.Errorf("line %q is no longer in todo.txt", change.After)
+		}
+		if change.Before == "" {
+			result = append(result[:idx], result[idx+1:]...)
+		} else {
+			result[idx] = change.Before
+			offset = idx - (change.Line - 1) // Lines added above since
+		}
+	}
+
+	// Removed lines go back to where they were, in order. Deleted todos
+	// leave a blank line behind, which they take again.
+	for _, change := range e.Changes {
+		if change.After != "" {
+			continue
+		}
+		idx := min(max(change.Line-1+offset, 0), len(result))
+		if idx < len(result) && strings.TrimSpace(result[idx]) == "" {
+			result[idx] = change.Before
+			continue
+		}
+		result = append(result[:idx], append([]string{change.Before}, result[idx:]...)...)
+	}
+
+	return result, nil
+}
+
+// Inverse returns an entry that undoes the changes of e, given the lines
+// before and after reverting it
+func (e JournalEntry) Inverse(op string, before, after []string) JournalEntry {
+	entry := NewJournalEntry(op, before, after)
+	entry.Archived = e.Restored
+	entry.Restored = e.Archived
+	return entry
+}
+
+// findLine returns the index of line in lines, preferring the hint index
+func findLine(lines []string, line string, hint int) int {
+	if hint >= 0 && hint < len(lines) && lines[hint] == line {
+		return hint
+	}
+	for idx, l := range lines {
+		if l == line {
+			return idx
+		}
+	}
+	return -1
+}
+
+// RevertEntry undoes the journal entry with the given ID in filename and
+// records the revert in the journal. Archived lines of the entry are taken
+// out of the archive files again and restored lines are archived again.
+func RevertEntry(filename string, id int, archive Archive) (JournalEntry, error) {
+	var revert JournalEntry
+	err := WithLock(filename, func() error {
+		entries, err := LoadJournal(filename)
+		if err != nil {
+			return err
+		}
+
+		var target *JournalEntry
+		for idx := range entries {
+			if entries[idx].ID == id {
+				target = &entries[idx]
+			}
+			if entries[idx].Reverts == id {
+				return fmt.Errorf("operation %d was already undone by operation %d", id, entries[idx].ID)
+			}
+		}
+		if target == nil {
+			return fmt.Errorf("%w: %d", ErrNoSuchEntry, id)
+		}
+
+		todos, err := LoadFromFile(filename)
+		if err != nil {
+			return err
+		}
+		before := Lines(todos)
+		after, err := target.Revert(before)
+		if err != nil {
+			return fmt.Errorf("can't undo operation %d: %w", id, err)
+		}
+
+		entry := target.Inverse(OpRevert, before, after)
+		entry.Reverts = id
+
+		if err := saveMoving(filename, todos, parseLines(after), archive, parseLines(entry.Archived), parseLines(entry.Restored)); err != nil {
+			return err
+		}
+
+		// The entry is returned even if the journal can't be written
+		entry.Time = time.Now()
+		revert = entry
+		written, err := AppendJournal(filename, entry)
+		if len(written) > 0 {
+			revert = written[0]
+		}
+		return err
+	})
+	return revert, err
+}
+
+// saveMoving saves todos to filename while moving lines between it and the
+// archive: archived todos are appended to the archive files first and
+// restored todos taken out of them last, so a crash in between leaves a task
+// in both places rather than in none. On errors, the steps already done are
+// rolled back and before is saved again. The caller must hold the lock.
+func saveMoving(filename string, before, todos []Item, archive Archive, archived, restored []Item) error {
+	if err := archive.Append(archived); err != nil {
+		return err
+	}
+	if err := SaveToFile(filename, todos); err != nil {
+		_ = archive.Remove(archived) // Best effort rollback, the save error matters more
+		return err
+	}
+	if err := archive.Remove(restored); err != nil {
+		// Best effort rollback, the archive error matters more
+		_ = SaveToFile(filename, before)
+		_ = archive.Remove(archived)
+		return err
+	}
+	return nil
+}
+
+// parseLines parses todo.txt lines into todos
+func parseLines(lines []string) []Item {
+	todos := make([]Item, len(lines))
+	for idx, line := range lines {
+		todos[idx] = Parse(line)
+	}
+	return todos
+}
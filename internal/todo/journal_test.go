@@ -0,0 +1,326 @@
+package todo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   []string
+		after    []string
+		expected []LineChange
+	}{
+		{
+			name:     "no changes",
+			before:   []string{"A", "B"},
+			after:    []string{"A", "B"},
+			expected: nil,
+		},
+		{
+			name:     "added line",
+			before:   []string{"A"},
+			after:    []string{"A", "B"},
+			expected: []LineChange{{Line: 2, After: "B"}},
+		},
+		{
+			name:     "removed line",
+			before:   []string{"A", "B", "C"},
+			after:    []string{"A", "C"},
+			expected: []LineChange{{Line: 2, Before: "B"}},
+		},
+		{
+			name:     "changed line",
+			before:   []string{"A", "B", "C"},
+			after:    []string{"A", "x B", "C"},
+			expected: []LineChange{{Line: 2, Before: "B", After: "x B"}},
+		},
+		{
+			name:   "completed recurring task",
+			before: []string{"A rec:1d", "B"},
+			after:  []string{"x A rec:1d", "B", "A rec:1d"},
+			expected: []LineChange{
+				{Line: 1, Before: "A rec:1d", After: "x A rec:1d"},
+				{Line: 3, After: "A rec:1d"},
+			},
+		},
+		{
+			name:     "blank lines are ignored",
+			before:   []string{"A", ""},
+			after:    []string{"A"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Diff(tt.before, tt.after)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Diff() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestJournalEntry_Revert(t *testing.T) {
+	before := []string{"A", "B", "C", "D"}
+	after := []string{"A", "x D", "E"}
+	entry := NewJournalEntry(OpEdit, before, after)
+
+	got, err := entry.Revert(after)
+	if err != nil {
+		t.Fatalf("Revert() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, before) {
+		t.Errorf("Revert() = %v, want %v", got, before)
+	}
+
+	// Later changes to other lines are kept
+	got, err = entry.Revert([]string{"New", "A", "x D", "E"})
+	if err != nil {
+		t.Fatalf("Revert() error = %v", err)
+	}
+	if strings.Join(got, ",") != "New,A,B,C,D" {
+		t.Errorf("Revert() with later changes = %v", got)
+	}
+
+	// Deleted todos take their blank line again
+	deleted := NewJournalEntry(OpDelete, []string{"A", "B", "C"}, []string{"A", "", "C"})
+	got, err = deleted.Revert([]string{"A", "", "C"})
+	if err != nil {
+		t.Fatalf("Revert() error = %v", err)
+	}
+	if strings.Join(got, ",") != "A,B,C" {
+		t.Errorf("Revert() of a deletion = %v", got)
+	}
+
+	// Lines produced by the operation must still be there
+	if _, err := entry.Revert([]string{"A"}); err == nil {
+		t.Error("Revert() should fail when changed lines are gone")
+	}
+}
+
+func TestUpdate_RecordsJournal(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "todo.txt")
+	if err := os.WriteFile(tmpFile, []byte("First task\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	for _, text := range []string{"Second task", "Third task"} {
+		err := Update(tmpFile, OpAdd, func(todos []Item) ([]Item, error) {
+			return append(todos, Parse(text)), nil
+		})
+		if err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+	}
+
+	// Operations that change nothing are not recorded
+	err := Update(tmpFile, OpEdit, func(todos []Item) ([]Item, error) {
+		return todos, nil
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	entries, err := LoadJournal(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadJournal() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 journal entries, got %d", len(entries))
+	}
+	if entries[1].ID != 2 || entries[1].Op != OpAdd || entries[1].Time.IsZero() {
+		t.Errorf("Unexpected entry %+v", entries[1])
+	}
+
+	// Undo the first add, the second one stays
+	revert, err := RevertEntry(tmpFile, 1, DefaultArchive(filepath.Dir(tmpFile)))
+	if err != nil {
+		t.Fatalf("RevertEntry() error = %v", err)
+	}
+	if revert.ID != 3 || revert.Op != OpRevert || revert.Reverts != 1 {
+		t.Errorf("Unexpected revert entry %+v", revert)
+	}
+	content, _ := os.ReadFile(tmpFile)
+	if string(content) != "First task\nThird task\n" {
+		t.Errorf("File after revert = %q", content)
+	}
+
+	if _, err := RevertEntry(tmpFile, 1, DefaultArchive(filepath.Dir(tmpFile))); err == nil {
+		t.Error("Reverting an operation twice should fail")
+	}
+	if _, err := RevertEntry(tmpFile, 42, DefaultArchive(filepath.Dir(tmpFile))); !errors.Is(err, ErrNoSuchEntry) {
+		t.Errorf("RevertEntry() error = %v, want %v", err, ErrNoSuchEntry)
+	}
+
+	// The revert itself can be undone
+	if _, err := RevertEntry(tmpFile, 3, DefaultArchive(filepath.Dir(tmpFile))); err != nil {
+		t.Fatalf("RevertEntry() error = %v", err)
+	}
+	content, _ = os.ReadFile(tmpFile)
+	if string(content) != "First task\nSecond task\nThird task\n" {
+		t.Errorf("File after reverting the revert = %q", content)
+	}
+}
+
+func TestRevertEntry_Archive(t *testing.T) {
+	dir := t.TempDir()
+	tmpFile := filepath.Join(dir, "todo.txt")
+	archiveFile := filepath.Join(dir, "todo_archive_2020_01.txt")
+	if err := os.WriteFile(tmpFile, []byte("Open task\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(archiveFile, []byte("x 2020-01-05 Old task\n"), 0644); err != nil {
+		t.Fatalf("Failed to create archive file: %v", err)
+	}
+
+	entry := NewJournalEntry(OpArchive, []string{"x 2020-01-05 Old task", "Open task"}, []string{"Open task"})
+	entry.Archived = []string{"x 2020-01-05 Old task"}
+	if _, err := AppendJournal(tmpFile, entry); err != nil {
+		t.Fatalf("AppendJournal() error = %v", err)
+	}
+
+	revert, err := RevertEntry(tmpFile, 1, DefaultArchive(filepath.Dir(tmpFile)))
+	if err != nil {
+		t.Fatalf("RevertEntry() error = %v", err)
+	}
+	if len(revert.Restored) != 1 {
+		t.Errorf("Expected revert to record the restored task, got %+v", revert)
+	}
+	content, _ := os.ReadFile(tmpFile)
+	if string(content) != "x 2020-01-05 Old task\nOpen task\n" {
+		t.Errorf("File after revert = %q", content)
+	}
+	if _, err := os.Stat(archiveFile); !os.IsNotExist(err) {
+		t.Errorf("Expected archived task to be taken out of the archive, got %v", err)
+	}
+}
+
+func TestLastJournalID(t *testing.T) {
+	longEntry := `{"id":7,"op":"archive","archived":["` + strings.Repeat("x", 10000) + `"]}`
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{"empty", "", 0},
+		{"blank lines", "\n\n", 0},
+		{"single entry", `{"id":1,"op":"add"}` + "\n", 1},
+		{"last of several", `{"id":1,"op":"add"}` + "\n" + `{"id":2,"op":"add"}` + "\n", 2},
+		{"trailing blank lines", `{"id":3,"op":"add"}` + "\n\n\n", 3},
+		{"no final newline", `{"id":1,"op":"add"}` + "\n" + `{"id":4,"op":"add"}`, 4},
+		{"long last entry", `{"id":6,"op":"add"}` + "\n" + longEntry + "\n", 7},
+		{"long only entry", longEntry, 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "todo_journal.jsonl")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create journal: %v", err)
+			}
+			got, err := lastJournalID(path)
+			if err != nil {
+				t.Fatalf("lastJournalID() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("lastJournalID() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	if got, err := lastJournalID(filepath.Join(t.TempDir(), "missing.jsonl")); got != 0 || err != nil {
+		t.Errorf("lastJournalID() of a missing journal = %d, %v", got, err)
+	}
+}
+
+func TestUpdate_JournalFailureIsWarning(t *testing.T) {
+	dir := t.TempDir()
+	tmpFile := filepath.Join(dir, "todo.txt")
+	if err := os.WriteFile(tmpFile, []byte("First task\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	// A directory in place of the journal can't be written
+	if err := os.Mkdir(JournalPath(tmpFile), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	err := Update(tmpFile, OpAdd, func(todos []Item) ([]Item, error) {
+		return append(todos, Parse("Second task")), nil
+	})
+	if !errors.Is(err, ErrJournal) {
+		t.Fatalf("Update() error = %v, want %v", err, ErrJournal)
+	}
+	content, _ := os.ReadFile(tmpFile)
+	if string(content) != "First task\nSecond task\n" {
+		t.Errorf("File after update = %q, the task should be saved", content)
+	}
+}
+
+func TestRevertEntry_JournalFailureReturnsEntry(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("file permissions don't apply to root")
+	}
+
+	tmpFile := filepath.Join(t.TempDir(), "todo.txt")
+	if err := os.WriteFile(tmpFile, []byte("First task\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	err := Update(tmpFile, OpAdd, func(todos []Item) ([]Item, error) {
+		return append(todos, Parse("Second task")), nil
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	// A read-only journal can be read, but not appended to
+	if err := os.Chmod(JournalPath(tmpFile), 0444); err != nil {
+		t.Fatalf("Failed to make the journal read-only: %v", err)
+	}
+	entry, err := RevertEntry(tmpFile, 1, DefaultArchive(filepath.Dir(tmpFile)))
+	if !errors.Is(err, ErrJournal) {
+		t.Fatalf("RevertEntry() error = %v, want %v", err, ErrJournal)
+	}
+	if entry.Reverts != 1 || len(entry.Changes) != 1 || entry.Changes[0].Before != "Second task" {
+		t.Errorf("RevertEntry() = %+v, want the revert of operation 1", entry)
+	}
+	if content, _ := os.ReadFile(tmpFile); string(content) != "First task\n" {
+		t.Errorf("File after revert = %q", content)
+	}
+}
+
+func TestRevertEntry_ArchiveFailureKeepsFile(t *testing.T) {
+	dir := t.TempDir()
+	tmpFile := filepath.Join(dir, "todo.txt")
+	if err := os.WriteFile(tmpFile, []byte("Open task\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	// A directory in place of the archive file can't be read
+	if err := os.Mkdir(filepath.Join(dir, "todo_archive_2020_01.txt"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	entry := NewJournalEntry(OpArchive, []string{"x 2020-01-05 Old task", "Open task"}, []string{"Open task"})
+	entry.Archived = []string{"x 2020-01-05 Old task"}
+	if _, err := AppendJournal(tmpFile, entry); err != nil {
+		t.Fatalf("AppendJournal() error = %v", err)
+	}
+
+	if _, err := RevertEntry(tmpFile, 1, DefaultArchive(dir)); err == nil {
+		t.Fatal("RevertEntry() should fail when the archive can't be changed")
+	}
+	content, _ := os.ReadFile(tmpFile)
+	if string(content) != "Open task\n" {
+		t.Errorf("File after failed revert = %q, want it unchanged", content)
+	}
+	entries, _ := LoadJournal(tmpFile)
+	if len(entries) != 1 {
+		t.Errorf("Expected the failed revert not to be recorded, got %d entries", len(entries))
+	}
+}
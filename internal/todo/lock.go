@@ -0,0 +1,112 @@
+package todo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrLocked is returned when another process holds the lock on todo.txt for
+// longer than the lock timeout
+var ErrLocked = errors.New("todo.txt is locked by another tada process, try again")
+
+// DefaultLockTimeout is how long to wait for another process to release the lock
+const DefaultLockTimeout = 5 * time.Second
+
+// lockRetryInterval is how often a held lock is retried
+const lockRetryInterval = 50 * time.Millisecond
+
+// FileLock is an advisory lock that coordinates read-modify-write cycles on
+// a todo file between tada processes
+type FileLock struct {
+	file *os.File
+}
+
+// lockPath returns the path of the lock file for a todo file
+// A separate lock file is used because todo.txt itself is replaced on every
+// save, which would silently drop a lock held on it. Symlinks are resolved
+// like writeFileAtomic does, so every link to the same file shares its lock.
+func lockPath(filename string) string {
+	if target, err := filepath.EvalSymlinks(filename); err == nil {
+		filename = target
+	}
+	return filepath.Join(filepath.Dir(filename), "."+filepath.Base(filename)+".lock")
+}
+
+// LockFile acquires the lock for filename, waiting up to timeout for another
+// process to release it. Returns ErrLocked if the lock is still held.
+func LockFile(filename string, timeout time.Duration) (*FileLock, error) {
+	file, err := os.OpenFile(lockPath(filename), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := tryLock(file)
+		if err != nil {
+			_ = file.Close() // Best effort close on error path
+			return nil, err
+		}
+		if locked {
+			return &FileLock{file: file}, nil
+		}
+		if time.Now().After(deadline) {
+			_ = file.Close() // Best effort close on error path
+			return nil, ErrLocked
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// Unlock releases the lock
+func (l *FileLock) Unlock() error {
+	if err := unlock(l.file); err != nil {
+		_ = l.file.Close() // Closing releases the lock as well
+		return err
+	}
+	return l.file.Close()
+}
+
+// WithLock runs fn while holding the lock for filename
+func WithLock(filename string, fn func() error) (err error) {
+	lock, err := LockFile(filename, DefaultLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if unlockErr := lock.Unlock(); unlockErr != nil && err == nil {
+			err = unlockErr
+		}
+	}()
+
+	return fn()
+}
+
+// Update loads the todos from filename, applies fn and saves the result,
+// all while holding the lock, so concurrent tada processes can't lose each
+// other's changes. Nothing is saved if fn returns an error. The change is
+// recorded in the journal as op, an error wrapping ErrJournal means that only
+// this record failed.
+func Update(filename string, op string, fn func(todos []Item) ([]Item, error)) error {
+	return WithLock(filename, func() error {
+		todos, err := LoadFromFile(filename)
+		if err != nil {
+			return err
+		}
+		before := Lines(todos)
+
+		todos, err = fn(todos)
+		if err != nil {
+			return err
+		}
+
+		if err := SaveToFile(filename, todos); err != nil {
+			return err
+		}
+
+		_, err = AppendJournal(filename, NewJournalEntry(op, before, Lines(todos)))
+		return err
+	})
+}
@@ -0,0 +1,154 @@
+package todo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLockFile_Contention(t *testing.T) {
+	if !lockingSupported {
+		t.Skip("file locking is not supported on this platform")
+	}
+
+	tmpFile := filepath.Join(t.TempDir(), "todo.txt")
+
+	first, err := LockFile(tmpFile, time.Second)
+	if err != nil {
+		t.Fatalf("LockFile() error = %v", err)
+	}
+
+	if _, err := LockFile(tmpFile, 100*time.Millisecond); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Second LockFile() error = %v, want %v", err, ErrLocked)
+	}
+
+	if err := first.Unlock(); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+
+	second, err := LockFile(tmpFile, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("LockFile() after Unlock() error = %v", err)
+	}
+	if err := second.Unlock(); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+}
+
+func TestLockFile_WaitsForRelease(t *testing.T) {
+	if !lockingSupported {
+		t.Skip("file locking is not supported on this platform")
+	}
+
+	tmpFile := filepath.Join(t.TempDir(), "todo.txt")
+
+	first, err := LockFile(tmpFile, time.Second)
+	if err != nil {
+		t.Fatalf("LockFile() error = %v", err)
+	}
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = first.Unlock()
+	}()
+
+	second, err := LockFile(tmpFile, 2*time.Second)
+	if err != nil {
+		t.Fatalf("LockFile() should wait for the lock to be released, error = %v", err)
+	}
+	_ = second.Unlock()
+}
+
+func TestLockFile_SharedThroughSymlinks(t *testing.T) {
+	if !lockingSupported {
+		t.Skip("file locking is not supported on this platform")
+	}
+
+	dir := t.TempDir()
+	tmpFile := filepath.Join(dir, "todo.txt")
+	if err := os.WriteFile(tmpFile, []byte("Task\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	linkDir := t.TempDir()
+	link := filepath.Join(linkDir, "tasks.txt")
+	if err := os.Symlink(tmpFile, link); err != nil {
+		t.Skipf("symlinks are not supported: %v", err)
+	}
+
+	first, err := LockFile(tmpFile, time.Second)
+	if err != nil {
+		t.Fatalf("LockFile() error = %v", err)
+	}
+	t.Cleanup(func() { _ = first.Unlock() })
+
+	if _, err := LockFile(link, 100*time.Millisecond); !errors.Is(err, ErrLocked) {
+		t.Errorf("LockFile() through a symlink error = %v, want %v", err, ErrLocked)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "todo.txt")
+	if err := os.WriteFile(tmpFile, []byte("First task\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	err := Update(tmpFile, "add", func(todos []Item) ([]Item, error) {
+		return append(todos, Parse("Second task")), nil
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	errAbort := errors.New("abort")
+	err = Update(tmpFile, "add", func(todos []Item) ([]Item, error) {
+		return nil, errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("Update() error = %v, want %v", err, errAbort)
+	}
+
+	content, err := os.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	if string(content) != "First task\nSecond task\n" {
+		t.Errorf("File content = %q", content)
+	}
+}
+
+func TestUpdate_ConcurrentWritersKeepAllChanges(t *testing.T) {
+	if !lockingSupported {
+		t.Skip("file locking is not supported on this platform")
+	}
+
+	tmpFile := filepath.Join(t.TempDir(), "todo.txt")
+	if err := os.WriteFile(tmpFile, nil, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	const writers = 10
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := Update(tmpFile, "add", func(todos []Item) ([]Item, error) {
+				return append(todos, Parse("Task")), nil
+			})
+			if err != nil {
+				t.Errorf("Update() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	todos, err := LoadFromFile(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+	if len(todos) != writers {
+		t.Errorf("Got %d todos, want %d", len(todos), writers)
+	}
+}
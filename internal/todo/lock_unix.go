@@ -0,0 +1,29 @@
+//go:build unix
+
+package todo
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// lockingSupported reports whether LockFile actually excludes other processes
+const lockingSupported = true
+
+// tryLock attempts to take an exclusive flock without blocking
+func tryLock(file *os.File) (bool, error) {
+	err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// unlock releases the flock
+func unlock(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,268 @@
+package todo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query is a parsed filter expression such as "@work +q4 pri:<=B due:<today -done"
+// An item matches if it matches every term.
+//
+// Terms:
+//
+//	@context, +project   the item has the context or project (ignoring case)
+//	pri:B, pri:A-C       the priority is B, or between A and C
+//	pri:<=B              compares priority letters, so A and B match
+//	due:<today           compares the due date with today, tomorrow,
+//	                     yesterday, a YYYY-MM-DD date or +3d, -1w relative
+//	                     to today; also t:, created: and completed:
+//	done                 the item is completed
+//	key:value            the item has the tag
+//	text                 the line contains the text (ignoring case)
+//
+// Any term can be negated with a leading "-" or "not:", which command lines
+// don't mistake for a flag.
+type Query struct {
+	expr  string
+	terms []queryTerm
+}
+
+// queryTerm is a single term of a query
+type queryTerm struct {
+	negate bool
+	match  func(item Item, now time.Time) bool
+}
+
+// dateFields are the query keys that compare dates
+var dateFields = map[string]func(Item) (time.Time, bool){
+	"due": Item.DueDate,
+	"t":   Item.ThresholdDate,
+	"created": func(i Item) (time.Time, bool) {
+		return parseDate(i.CreationDate)
+	},
+	"completed": func(i Item) (time.Time, bool) {
+		return parseDate(i.CompletionDate)
+	},
+}
+
+// ParseQuery parses a filter expression
+// An empty expression matches every item.
+func ParseQuery(expr string) (Query, error) {
+	query := Query{expr: strings.TrimSpace(expr)}
+
+	for _, token := range strings.Fields(expr) {
+		term := queryTerm{}
+		if len(token) > 1 && strings.HasPrefix(token, "-") {
+			term.negate = true
+			token = token[1:]
+		} else if len(token) > len("not:") && strings.HasPrefix(token, "not:") {
+			term.negate = true
+			token = token[len("not:"):]
+		}
+
+		match, err := parseTerm(token)
+		if err != nil {
+			return Query{}, err
+		}
+		term.match = match
+		query.terms = append(query.terms, term)
+	}
+
+	return query, nil
+}
+
+// parseTerm parses a single term without its negation
+func parseTerm(token string) (func(Item, time.Time) bool, error) {
+	switch {
+	case token == "done":
+		return func(item Item, _ time.Time) bool {
+			return item.Completed
+		}, nil
+	case len(token) > 1 && strings.HasPrefix(token, "@"):
+		context := token[1:]
+		return func(item Item, _ time.Time) bool {
+			return containsFold(item.Contexts, context)
+		}, nil
+	case len(token) > 1 && strings.HasPrefix(token, "+"):
+		project := token[1:]
+		return func(item Item, _ time.Time) bool {
+			return containsFold(item.Projects, project)
+		}, nil
+	}
+
+	key, value, found := strings.Cut(token, ":")
+	if found && key != "" && value != "" {
+		if key == "pri" {
+			return parsePriorityTerm(value)
+		}
+		if dateOf, ok := dateFields[key]; ok {
+			return parseDateTerm(key, value, dateOf)
+		}
+		if _, _, ok := splitTag(token); ok {
+			return func(item Item, _ time.Time) bool {
+				tagValue, ok := item.Tag(key)
+				return ok && tagValue == value
+			}, nil
+		}
+	}
+
+	// Anything else is text to look for
+	text := strings.ToLower(token)
+	return func(item Item, _ time.Time) bool {
+		return strings.Contains(strings.ToLower(item.String()), text)
+	}, nil
+}
+
+// splitComparison splits a leading <, <=, >, >= or = from value
+func splitComparison(value string) (string, string) {
+	for _, op := range []string{"<=", ">=", "<", ">", "="} {
+		if strings.HasPrefix(value, op) {
+			return op, value[len(op):]
+		}
+	}
+	return "=", value
+}
+
+// compare applies a comparison operator to the result of a three-way comparison
+func compare(op string, cmp int) bool {
+	switch op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	default:
+		return cmp == 0
+	}
+}
+
+// parsePriorityTerm parses the value of a pri: term
+func parsePriorityTerm(value string) (func(Item, time.Time) bool, error) {
+	value = strings.ToUpper(value)
+
+	// Range such as A-C
+	if from, to, isRange := strings.Cut(value, "-"); isRange {
+		if !isPriorityLetter(from) || !isPriorityLetter(to) || from > to {
+			return nil, fmt.Errorf("invalid priority range %q, use a range like A-C", value)
+		}
+		return func(item Item, _ time.Time) bool {
+			return item.Priority != "" && item.Priority >= from && item.Priority <= to
+		}, nil
+	}
+
+	op, letter := splitComparison(value)
+	if !isPriorityLetter(letter) {
+		return nil, fmt.Errorf("invalid priority %q, use a letter from A to Z", letter)
+	}
+	return func(item Item, _ time.Time) bool {
+		return item.Priority != "" && compare(op, strings.Compare(item.Priority, letter))
+	}, nil
+}
+
+// isPriorityLetter reports whether s is a single letter from A to Z
+func isPriorityLetter(s string) bool {
+	return len(s) == 1 && s[0] >= 'A' && s[0] <= 'Z'
+}
+
+// parseDateTerm parses the value of a date term such as due:<today
+func parseDateTerm(key, value string, dateOf func(Item) (time.Time, bool)) (func(Item, time.Time) bool, error) {
+	op, dateValue := splitComparison(value)
+	offset, err := parseRelativeDate(dateValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date in %s:%s: %w", key, value, err)
+	}
+
+	return func(item Item, now time.Time) bool {
+		date, ok := dateOf(item)
+		if !ok {
+			return false
+		}
+		// DaysUntil is negative for dates before the one we compare with
+		return compare(op, DaysUntil(date, offset(now)))
+	}, nil
+}
+
+// parseRelativeDate parses today, tomorrow, yesterday, YYYY-MM-DD or an
+// offset from today like +3d, -1w. It returns a function resolving the date
+// for a given day.
+func parseRelativeDate(value string) (func(now time.Time) time.Time, error) {
+	switch value {
+	case "today":
+		return func(now time.Time) time.Time { return now }, nil
+	case "tomorrow":
+		return func(now time.Time) time.Time { return now.AddDate(0, 0, 1) }, nil
+	case "yesterday":
+		return func(now time.Time) time.Time { return now.AddDate(0, 0, -1) }, nil
+	}
+
+	if date, ok := parseDate(value); ok {
+		return func(time.Time) time.Time { return date }, nil
+	}
+
+	// Offsets like +3d or -2w
+	if len(value) >= 3 && (value[0] == '+' || value[0] == '-') {
+		amount, err := strconv.Atoi(value[1 : len(value)-1])
+		if err == nil {
+			if value[0] == '-' {
+				amount = -amount
+			}
+			switch value[len(value)-1] {
+			case 'd':
+				return func(now time.Time) time.Time { return now.AddDate(0, 0, amount) }, nil
+			case 'w':
+				return func(now time.Time) time.Time { return now.AddDate(0, 0, 7*amount) }, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("use today, tomorrow, yesterday, YYYY-MM-DD or an offset like +3d")
+}
+
+// parseDate parses a YYYY-MM-DD date
+func parseDate(value string) (time.Time, bool) {
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// containsFold reports whether values contains value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns true if the item matches every term of the query
+func (q Query) Match(item Item) bool {
+	return q.MatchAt(item, time.Now())
+}
+
+// MatchAt is like Match, with relative dates resolved against now
+func (q Query) MatchAt(item Item, now time.Time) bool {
+	for _, term := range q.terms {
+		if term.match(item, now) == term.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// IsEmpty returns true if the query has no terms and matches everything
+func (q Query) IsEmpty() bool {
+	return len(q.terms) == 0
+}
+
+// String returns the expression the query was parsed from
+func (q Query) String() string {
+	return q.expr
+}
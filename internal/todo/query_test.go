@@ -0,0 +1,78 @@
+package todo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuery_Match(t *testing.T) {
+	now := time.Date(2025, 10, 15, 9, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name  string
+		query string
+		line  string
+		match bool
+	}{
+		{name: "empty query", query: "", line: "Anything", match: true},
+		{name: "context", query: "@work", line: "Report @Work", match: true},
+		{name: "missing context", query: "@work", line: "Report @Home", match: false},
+		{name: "project", query: "+q4", line: "Report +Q4", match: true},
+		{name: "priority", query: "pri:B", line: "(B) Report", match: true},
+		{name: "priority at most", query: "pri:<=B", line: "(A) Report", match: true},
+		{name: "priority below", query: "pri:<=B", line: "(C) Report", match: false},
+		{name: "priority after", query: "pri:>B", line: "(C) Report", match: true},
+		{name: "no priority never compares", query: "pri:>=A", line: "Report", match: false},
+		{name: "priority range", query: "pri:a-c", line: "(B) Report", match: true},
+		{name: "overdue", query: "due:<today", line: "Report due:2025-10-14", match: true},
+		{name: "due today is not overdue", query: "due:<today", line: "Report due:2025-10-15", match: false},
+		{name: "due today", query: "due:today", line: "Report due:2025-10-15", match: true},
+		{name: "due tomorrow or earlier", query: "due:<=tomorrow", line: "Report due:2025-10-16", match: true},
+		{name: "due within a week", query: "due:<+1w", line: "Report due:2025-10-21", match: true},
+		{name: "due after date", query: "due:>2025-10-01", line: "Report due:2025-10-02", match: true},
+		{name: "no due date never compares", query: "due:<today", line: "Report", match: false},
+		{name: "threshold", query: "t:>today", line: "Report t:2025-11-01", match: true},
+		{name: "created", query: "created:yesterday", line: "2025-10-14 Report", match: true},
+		{name: "completed", query: "completed:>=-1w", line: "x 2025-10-10 Report", match: true},
+		{name: "done", query: "done", line: "x 2025-10-10 Report", match: true},
+		{name: "not done", query: "-done", line: "x 2025-10-10 Report", match: false},
+		{name: "not: done", query: "not:done", line: "x 2025-10-10 Report", match: false},
+		{name: "not: context", query: "not:@work", line: "Report @Home", match: true},
+		{name: "tag", query: "id:42", line: "Report id:42", match: true},
+		{name: "other tag value", query: "id:42", line: "Report id:43", match: false},
+		{name: "text", query: "REPORT", line: "Write report", match: true},
+		{name: "negated text", query: "-report", line: "Write report", match: false},
+		{
+			name:  "combined",
+			query: "@work +q4 pri:<=B due:<today -done",
+			line:  "(A) Report @work +Q4 due:2025-10-01",
+			match: true,
+		},
+		{
+			name:  "combined with one failing term",
+			query: "@work +q4 pri:<=B due:<today -done",
+			line:  "(C) Report @work +Q4 due:2025-10-01",
+			match: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery(%q) error = %v", tt.query, err)
+			}
+			if got := query.MatchAt(Parse(tt.line), now); got != tt.match {
+				t.Errorf("Query %q matching %q = %v, want %v", tt.query, tt.line, got, tt.match)
+			}
+		})
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	for _, expr := range []string{"pri:1", "pri:C-A", "pri:<=AB", "due:<soon", "t:+3x"} {
+		if _, err := ParseQuery(expr); err == nil {
+			t.Errorf("ParseQuery(%q) should fail", expr)
+		}
+	}
+}
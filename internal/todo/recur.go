@@ -0,0 +1,139 @@
+package todo
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Recurrence describes the interval of a rec: tag such as rec:1w or rec:+1m
+type Recurrence struct {
+	// Strict recurrences advance from the previous due date ("+" prefix),
+	// relative ones from the completion date
+	Strict bool
+	Amount int
+	// Unit is one of d (days), b (business days), w (weeks), m (months) or y (years)
+	Unit byte
+}
+
+// ParseRecurrence parses the value of a rec: tag, e.g. "3d", "1w" or "+1m"
+func ParseRecurrence(value string) (Recurrence, error) {
+	var rec Recurrence
+
+	if len(value) > 0 && value[0] == '+' {
+		rec.Strict = true
+		value = value[1:]
+	}
+	if len(value) < 2 {
+		return Recurrence{}, fmt.Errorf("invalid recurrence %q", value)
+	}
+
+	rec.Unit = value[len(value)-1]
+	switch rec.Unit {
+	case 'd', 'b', 'w', 'm', 'y':
+	default:
+		return Recurrence{}, fmt.Errorf("invalid recurrence unit %q", string(rec.Unit))
+	}
+
+	amount, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || amount <= 0 {
+		return Recurrence{}, fmt.Errorf("invalid recurrence amount %q", value[:len(value)-1])
+	}
+	rec.Amount = amount
+
+	return rec, nil
+}
+
+// Advance returns date moved forward by the recurrence interval
+func (r Recurrence) Advance(date time.Time) time.Time {
+	switch r.Unit {
+	case 'b':
+		return addBusinessDays(date, r.Amount)
+	case 'w':
+		return date.AddDate(0, 0, 7*r.Amount)
+	case 'm':
+		return addMonths(date, r.Amount)
+	case 'y':
+		return addMonths(date, 12*r.Amount)
+	default:
+		return date.AddDate(0, 0, r.Amount)
+	}
+}
+
+// addMonths adds months to date, clamping the day to the end of the target
+// month so that Jan 31 + 1 month is Feb 28 (or 29) instead of Mar 3
+func addMonths(date time.Time, months int) time.Time {
+	firstOfTarget := time.Date(date.Year(), date.Month()+time.Month(months), 1, 0, 0, 0, 0, date.Location())
+	lastDay := firstOfTarget.AddDate(0, 1, -1).Day()
+
+	day := date.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return firstOfTarget.AddDate(0, 0, day-1)
+}
+
+// addBusinessDays adds days to date, skipping Saturdays and Sundays
+func addBusinessDays(date time.Time, days int) time.Time {
+	for days > 0 {
+		date = date.AddDate(0, 0, 1)
+		if date.Weekday() != time.Saturday && date.Weekday() != time.Sunday {
+			days--
+		}
+	}
+	return date
+}
+
+// NextRecurrence returns the follow-up task for an item with a rec: tag that
+// is completed on the given date. The copy is open, created on the completion
+// date, and has its due: and t: dates advanced by the recurrence interval.
+// Returns false if the item has no valid rec: tag.
+func (i Item) NextRecurrence(completed time.Time) (Item, bool) {
+	value, ok := i.Tag("rec")
+	if !ok {
+		return Item{}, false
+	}
+	rec, err := ParseRecurrence(value)
+	if err != nil {
+		return Item{}, false
+	}
+
+	today := time.Date(completed.Year(), completed.Month(), completed.Day(), 0, 0, 0, 0, time.UTC)
+	due, hasDue := i.DueDate()
+	threshold, hasThreshold := i.ThresholdDate()
+
+	next := i
+	next.Completed = false
+	next.CompletionDate = ""
+	next.CreationDate = today.Format("2006-01-02")
+	next.Tags = make(map[string]string, len(i.Tags))
+	for key, value := range i.Tags {
+		next.Tags[key] = value
+	}
+
+	if rec.Strict {
+		// Strict: every date moves forward from its previous value
+		if hasDue {
+			next.SetTag("due", rec.Advance(due).Format("2006-01-02"))
+		}
+		if hasThreshold {
+			next.SetTag("t", rec.Advance(threshold).Format("2006-01-02"))
+		}
+	} else {
+		// Relative: the due date moves forward from today, the threshold
+		// keeps its distance to the due date
+		newDue := rec.Advance(today)
+		if hasDue {
+			next.SetTag("due", newDue.Format("2006-01-02"))
+		}
+		if hasThreshold {
+			newThreshold := newDue
+			if hasDue {
+				newThreshold = newDue.Add(threshold.Sub(due))
+			}
+			next.SetTag("t", newThreshold.Format("2006-01-02"))
+		}
+	}
+
+	return next.Normalize(), true
+}
@@ -0,0 +1,156 @@
+package todo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		value     string
+		expected  Recurrence
+		expectErr bool
+	}{
+		{value: "3d", expected: Recurrence{Amount: 3, Unit: 'd'}},
+		{value: "1w", expected: Recurrence{Amount: 1, Unit: 'w'}},
+		{value: "+1m", expected: Recurrence{Strict: true, Amount: 1, Unit: 'm'}},
+		{value: "2y", expected: Recurrence{Amount: 2, Unit: 'y'}},
+		{value: "+5b", expected: Recurrence{Strict: true, Amount: 5, Unit: 'b'}},
+		{value: "12d", expected: Recurrence{Amount: 12, Unit: 'd'}},
+		{value: "d", expectErr: true},
+		{value: "0d", expectErr: true},
+		{value: "-1d", expectErr: true},
+		{value: "1x", expectErr: true},
+		{value: "+", expectErr: true},
+		{value: "", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			rec, err := ParseRecurrence(tt.value)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("ParseRecurrence(%q) should return an error", tt.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRecurrence(%q) error = %v", tt.value, err)
+			}
+			if rec != tt.expected {
+				t.Errorf("ParseRecurrence(%q) = %+v, want %+v", tt.value, rec, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRecurrenceAdvance(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		date     string
+		expected string
+	}{
+		{name: "days", value: "3d", date: "2025-10-30", expected: "2025-11-02"},
+		{name: "weeks", value: "2w", date: "2025-10-01", expected: "2025-10-15"},
+		{name: "months", value: "1m", date: "2025-10-15", expected: "2025-11-15"},
+		{name: "months clamp to end of month", value: "1m", date: "2025-01-31", expected: "2025-02-28"},
+		{name: "months clamp in leap year", value: "1m", date: "2024-01-31", expected: "2024-02-29"},
+		{name: "years", value: "1y", date: "2024-02-29", expected: "2025-02-28"},
+		{name: "business days skip weekend", value: "1b", date: "2025-10-17", expected: "2025-10-20"},
+		{name: "business days within week", value: "3b", date: "2025-10-13", expected: "2025-10-16"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, err := ParseRecurrence(tt.value)
+			if err != nil {
+				t.Fatalf("ParseRecurrence(%q) error = %v", tt.value, err)
+			}
+			date, _ := time.Parse("2006-01-02", tt.date)
+			if got := rec.Advance(date).Format("2006-01-02"); got != tt.expected {
+				t.Errorf("Advance(%s) = %s, want %s", tt.date, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNextRecurrence(t *testing.T) {
+	completed := time.Date(2025, 10, 17, 18, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		line     string
+		expected string
+		ok       bool
+	}{
+		{
+			name:     "relative recurrence moves due date from completion",
+			line:     "(A) 2025-10-01 Water plants @Home due:2025-10-10 rec:1w",
+			expected: "(A) 2025-10-17 Water plants @Home due:2025-10-24 rec:1w",
+			ok:       true,
+		},
+		{
+			name:     "strict recurrence moves due date from previous due date",
+			line:     "2025-09-01 Pay rent due:2025-10-01 rec:+1m",
+			expected: "2025-10-17 Pay rent due:2025-11-01 rec:+1m",
+			ok:       true,
+		},
+		{
+			name:     "strict recurrence moves threshold too",
+			line:     "Pay rent t:2025-09-25 due:2025-10-01 rec:+1m",
+			expected: "2025-10-17 Pay rent t:2025-10-25 due:2025-11-01 rec:+1m",
+			ok:       true,
+		},
+		{
+			name:     "relative recurrence keeps threshold distance to due date",
+			line:     "Review budget t:2025-10-08 due:2025-10-10 rec:2w",
+			expected: "2025-10-17 Review budget t:2025-10-29 due:2025-10-31 rec:2w",
+			ok:       true,
+		},
+		{
+			name:     "relative recurrence with only a threshold",
+			line:     "Call mom t:2025-10-10 rec:3d",
+			expected: "2025-10-17 Call mom t:2025-10-20 rec:3d",
+			ok:       true,
+		},
+		{
+			name:     "recurrence without dates only gets a new creation date",
+			line:     "Stretch rec:1d",
+			expected: "2025-10-17 Stretch rec:1d",
+			ok:       true,
+		},
+		{
+			name: "no rec tag",
+			line: "Buy milk due:2025-10-10",
+			ok:   false,
+		},
+		{
+			name: "invalid rec tag",
+			line: "Buy milk rec:often",
+			ok:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := Parse(tt.line)
+			next, ok := item.NextRecurrence(completed)
+			if ok != tt.ok {
+				t.Fatalf("NextRecurrence() ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if got := next.String(); got != tt.expected {
+				t.Errorf("NextRecurrence() = %q, want %q", got, tt.expected)
+			}
+			if next.Completed {
+				t.Error("Recurring copy should not be completed")
+			}
+			if item.String() != tt.line {
+				t.Errorf("Original item was modified: %q", item.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package todo
+
+import (
+	"sort"
+	"strings"
+)
+
+// splitTag splits a key:value token into its key and value
+// Both parts must be non-empty and free of colons. URLs such as
+// https://example.com and the pri:X priority tag are not treated as tags.
+func splitTag(token string) (string, string, bool) {
+	key, value, found := strings.Cut(token, ":")
+	if !found || key == "" || value == "" {
+		return "", "", false
+	}
+	if strings.Contains(value, ":") || strings.HasPrefix(value, "//") {
+		return "", "", false
+	}
+	if strings.HasPrefix(key, "@") || strings.HasPrefix(key, "+") || isPriorityTag(token) {
+		return "", "", false
+	}
+	return key, value, true
+}
+
+// Tag returns the value of a key:value tag and whether the item has it
+func (i Item) Tag(key string) (string, bool) {
+	value, ok := i.Tags[key]
+	return value, ok
+}
+
+// SetTag adds a key:value tag or changes the value of an existing one
+// Keys and values must not contain spaces or colons.
+func (i *Item) SetTag(key, value string) {
+	if i.Tags == nil {
+		i.Tags = make(map[string]string)
+	}
+	i.Tags[key] = value
+}
+
+// RemoveTag removes a key:value tag from the item
+func (i *Item) RemoveTag(key string) {
+	delete(i.Tags, key)
+}
+
+// TagKeys returns the keys of all tags on the item in alphabetical order
+func (i Item) TagKeys() []string {
+	keys := make([]string, 0, len(i.Tags))
+	for key := range i.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
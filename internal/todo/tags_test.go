@@ -0,0 +1,111 @@
+package todo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse_Tags(t *testing.T) {
+	tests := []struct {
+		name         string
+		line         string
+		expectedTags map[string]string
+	}{
+		{
+			name:         "no tags",
+			line:         "Buy milk @Store",
+			expectedTags: map[string]string{},
+		},
+		{
+			name:         "due and threshold tags",
+			line:         "Pay rent due:2025-10-01 t:2025-09-25 @Home",
+			expectedTags: map[string]string{"due": "2025-10-01", "t": "2025-09-25"},
+		},
+		{
+			name:         "custom tags",
+			line:         "(B) Fix bug id:42 rec:+1w owner:jj",
+			expectedTags: map[string]string{"id": "42", "rec": "+1w", "owner": "jj"},
+		},
+		{
+			name:         "pri tag is not a generic tag",
+			line:         "Learn Go pri:B",
+			expectedTags: map[string]string{},
+		},
+		{
+			name:         "urls and clock times are not tags",
+			line:         "Read https://example.com at 10:30:00 key: :value",
+			expectedTags: map[string]string{},
+		},
+		{
+			name:         "first occurrence of a key wins",
+			line:         "Call id:1 id:2",
+			expectedTags: map[string]string{"id": "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := Parse(tt.line)
+			if !reflect.DeepEqual(item.Tags, tt.expectedTags) {
+				t.Errorf("Tags = %v, want %v", item.Tags, tt.expectedTags)
+			}
+			if item.Description == "" {
+				t.Error("Tags should remain part of the description")
+			}
+		})
+	}
+}
+
+func TestTagAccessors(t *testing.T) {
+	item := Parse("Pay rent due:2025-10-01 @Home id:7")
+
+	if value, ok := item.Tag("due"); !ok || value != "2025-10-01" {
+		t.Errorf("Tag(due) = %q, %v, want %q, true", value, ok, "2025-10-01")
+	}
+	if _, ok := item.Tag("t"); ok {
+		t.Error("Tag(t) should not exist")
+	}
+
+	item.SetTag("due", "2025-11-01")
+	item.SetTag("t", "2025-10-25")
+	item.RemoveTag("id")
+
+	expected := "Pay rent due:2025-11-01 @Home t:2025-10-25"
+	if got := item.String(); got != expected {
+		t.Errorf("String() = %q, want %q", got, expected)
+	}
+
+	if keys := item.TagKeys(); !reflect.DeepEqual(keys, []string{"due", "t"}) {
+		t.Errorf("TagKeys() = %v, want [due t]", keys)
+	}
+}
+
+func TestSetTag_NilMap(t *testing.T) {
+	item := Item{Description: "Water plants"}
+	item.SetTag("rec", "3d")
+
+	if got := item.String(); got != "Water plants rec:3d" {
+		t.Errorf("String() = %q, want %q", got, "Water plants rec:3d")
+	}
+}
+
+func TestString_TagsRoundTrip(t *testing.T) {
+	lines := []string{
+		"Pay rent due:2025-10-01 t:2025-09-25 @Home",
+		"(B) 2025-09-01 Fix bug id:42 rec:+1w +Tada",
+		"Call id:1 id:2",
+		"x 2025-09-02 2025-09-01 Ship release due:2025-09-01 pri:A",
+	}
+
+	for _, line := range lines {
+		t.Run(line, func(t *testing.T) {
+			item := Parse(line)
+			if got := item.String(); got != line {
+				t.Errorf("Parse(%q).String() = %q", line, got)
+			}
+			if reparsed := Parse(item.String()); !reflect.DeepEqual(reparsed, item) {
+				t.Errorf("Parse(item.String()) = %+v, want %+v", reparsed, item)
+			}
+		})
+	}
+}
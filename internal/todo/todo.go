@@ -3,30 +3,54 @@ package todo
 import (
 	"bufio"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 )
 
+// datePattern matches the YYYY-MM-DD dates used by todo.txt
+var datePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
 // Item represents a todo item following the todo.txt format
 type Item struct {
-	Raw            string
-	Completed      bool
-	Priority       string
-	CompletionDate string
-	CreationDate   string
-	Description    string
-	Contexts       []string
-	Projects       []string
+	Raw            string            `json:"-"`
+	Completed      bool              `json:"completed"`
+	Priority       string            `json:"priority,omitempty"`
+	CompletionDate string            `json:"completion_date,omitempty"`
+	CreationDate   string            `json:"creation_date,omitempty"`
+	Description    string            `json:"description"`
+	Contexts       []string          `json:"contexts"`
+	Projects       []string          `json:"projects"`
+	Tags           map[string]string `json:"tags"` // key:value tags such as due:2025-10-01, except pri
+
+	parsed *Item // Snapshot of the fields as Parse read them from Raw
 }
 
 // Parse parses a todo.txt line into an Item
 func Parse(line string) Item {
+	item := parseFields(line)
+
+	// Keep a copy of the fields, so String can tell whether they changed
+	snapshot := item
+	snapshot.Contexts = slices.Clone(item.Contexts)
+	snapshot.Projects = slices.Clone(item.Projects)
+	snapshot.Tags = maps.Clone(item.Tags)
+	item.parsed = &snapshot
+
+	return item
+}
+
+// parseFields parses the fields of a todo.txt line
+func parseFields(line string) Item {
 	item := Item{
 		Raw:      line,
 		Contexts: []string{},
 		Projects: []string{},
+		Tags:     map[string]string{},
 	}
 
 	if strings.TrimSpace(line) == "" {
@@ -58,9 +82,6 @@ func Parse(line string) Item {
 		}
 	}
 
-	// Date regex pattern
-	datePattern := regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
-
 	// Check for completion date (only if completed)
 	if item.Completed && datePattern.MatchString(parts[idx]) {
 		item.CompletionDate = parts[idx]
@@ -79,15 +100,20 @@ func Parse(line string) Item {
 		}
 	}
 
-	// Rest is description with contexts and projects
+	// Rest is description with contexts, projects and tags
 	descParts := parts[idx:]
 	for _, part := range descParts {
 		if strings.HasPrefix(part, "@") {
 			item.Contexts = append(item.Contexts, part[1:])
 		} else if strings.HasPrefix(part, "+") {
 			item.Projects = append(item.Projects, part[1:])
-		} else if strings.HasPrefix(part, "pri:") && len(part) == 5 {
+		} else if isPriorityTag(part) {
 			item.Priority = string(part[4])
+		} else if key, value, ok := splitTag(part); ok {
+			// The first occurrence of a key wins
+			if _, exists := item.Tags[key]; !exists {
+				item.Tags[key] = value
+			}
 		}
 	}
 
@@ -97,8 +123,166 @@ func Parse(line string) Item {
 }
 
 // String returns the formatted todo.txt string
+// Items that were not changed since they were parsed are written back as
+// their Raw line, spacing and all. Otherwise the line is rebuilt from the
+// structured fields, so changes to Completed, Priority, dates, Contexts,
+// Projects or Tags are reflected without touching Raw. Tokens of the
+// description keep their original order; unknown tokens are written back
+// untouched.
 func (i Item) String() string {
-	return i.Raw
+	if i.isUnchanged() {
+		return i.Raw
+	}
+
+	var parts []string
+
+	if i.Completed {
+		parts = append(parts, "x")
+		if i.CompletionDate != "" {
+			parts = append(parts, i.CompletionDate)
+		}
+	}
+
+	body, priorityWritten := i.bodyTokens()
+
+	// Open tasks carry their priority as a "(A)" prefix, unless the
+	// description already holds it as a pri:X tag
+	if !i.Completed && i.Priority != "" && !priorityWritten {
+		parts = append(parts, "("+i.Priority+")")
+		priorityWritten = true
+	}
+
+	if i.CreationDate != "" {
+		parts = append(parts, i.CreationDate)
+	}
+
+	parts = append(parts, body...)
+
+	// Completed tasks keep their priority as a pri:X tag (todo.txt convention)
+	if !priorityWritten && i.Priority != "" {
+		parts = append(parts, "pri:"+i.Priority)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// isUnchanged reports whether the item still holds what Parse read from Raw
+// Items that were not made by Parse are always rebuilt from their fields.
+func (i Item) isUnchanged() bool {
+	parsed := i.parsed
+	if parsed == nil {
+		return false
+	}
+	return i.Completed == parsed.Completed &&
+		i.Priority == parsed.Priority &&
+		i.CompletionDate == parsed.CompletionDate &&
+		i.CreationDate == parsed.CreationDate &&
+		i.Description == parsed.Description &&
+		slices.Equal(i.Contexts, parsed.Contexts) &&
+		slices.Equal(i.Projects, parsed.Projects) &&
+		maps.Equal(i.Tags, parsed.Tags)
+}
+
+// bodyTokens reconciles the description tokens with the structured fields
+// It returns the tokens to write and whether a pri:X tag was written
+func (i Item) bodyTokens() ([]string, bool) {
+	contexts := countTokens(i.Contexts)
+	projects := countTokens(i.Projects)
+	tagsWritten := make(map[string]bool, len(i.Tags))
+	priorityWritten := false
+
+	// Parse lets the last pri:X tag win, so that is the one to rewrite
+	parts := strings.Fields(i.Description)
+	lastPriority := -1
+	for idx, part := range parts {
+		if isPriorityTag(part) {
+			lastPriority = idx
+		}
+	}
+
+	var tokens []string
+	for idx, part := range parts {
+		switch {
+		case strings.HasPrefix(part, "@"):
+			// Drop contexts that were removed from the item
+			if contexts[part[1:]] == 0 {
+				continue
+			}
+			contexts[part[1:]]--
+		case strings.HasPrefix(part, "+"):
+			// Drop projects that were removed from the item
+			if projects[part[1:]] == 0 {
+				continue
+			}
+			projects[part[1:]]--
+		case isPriorityTag(part):
+			// Rewrite the priority tag in place, or drop it if cleared
+			if i.Priority == "" {
+				continue
+			}
+			if idx == lastPriority {
+				part = "pri:" + i.Priority
+				priorityWritten = true
+			}
+		default:
+			key, _, ok := splitTag(part)
+			if !ok {
+				break
+			}
+			// Drop tags that were removed from the item
+			value, exists := i.Tags[key]
+			if !exists {
+				continue
+			}
+			// Rewrite the first occurrence, later duplicates are kept as is
+			if !tagsWritten[key] {
+				part = key + ":" + value
+				tagsWritten[key] = true
+			}
+		}
+		tokens = append(tokens, part)
+	}
+
+	// Append contexts and projects that are not yet in the description
+	for _, context := range i.Contexts {
+		if contexts[context] > 0 {
+			tokens = append(tokens, "@"+context)
+			contexts[context]--
+		}
+	}
+	for _, project := range i.Projects {
+		if projects[project] > 0 {
+			tokens = append(tokens, "+"+project)
+			projects[project]--
+		}
+	}
+	for _, key := range i.TagKeys() {
+		if !tagsWritten[key] {
+			tokens = append(tokens, key+":"+i.Tags[key])
+		}
+	}
+
+	return tokens, priorityWritten
+}
+
+// isPriorityTag reports whether a token is a pri:X tag
+func isPriorityTag(token string) bool {
+	return strings.HasPrefix(token, "pri:") && len(token) == 5
+}
+
+// countTokens counts how often each value occurs
+func countTokens(values []string) map[string]int {
+	counts := make(map[string]int, len(values))
+	for _, value := range values {
+		counts[value]++
+	}
+	return counts
+}
+
+// Normalize serializes the item and parses it again, so that Raw and
+// Description reflect changes made to the structured fields
+func (i Item) Normalize() Item {
+	return Parse(i.String())
 }
 
 // LoadFromFile loads todos from a file
@@ -124,105 +308,46 @@ func LoadFromFile(filename string) ([]Item, error) {
 }
 
 // SaveToFile saves todos to a file
-func SaveToFile(filename string, items []Item) (err error) {
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if closeErr := file.Close(); closeErr != nil && err == nil {
-			err = closeErr
-		}
-	}()
-
-	writer := bufio.NewWriter(file)
-	for _, item := range items {
-		_, err := fmt.Fprintln(writer, item.String())
-		if err != nil {
-			return err
+// The file is replaced atomically: either all todos are written or the
+// previous content is left untouched.
+func SaveToFile(filename string, items []Item) error {
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		for _, item := range items {
+			if _, err := fmt.Fprintln(w, item.String()); err != nil {
+				return err
+			}
 		}
-	}
-
-	return writer.Flush()
+		return nil
+	})
 }
 
-// IsCompletedOlderThanDays checks if a completed todo is older than the specified number of days
+// IsCompletedOlderThanDays checks if a completed todo was completed at least
+// the specified number of local calendar days ago, today being 0 days ago
 func (i Item) IsCompletedOlderThanDays(days int) bool {
+	age, ok := i.completedDaysAgo(time.Now())
+	return ok && age >= days
+}
+
+// completedDaysAgo returns the number of calendar days from the completion
+// date to now, and false if the todo has no valid completion date
+func (i Item) completedDaysAgo(now time.Time) (int, bool) {
 	if !i.Completed || i.CompletionDate == "" {
-		return false
+		return 0, false
 	}
 
-	completionTime, err := time.Parse("2006-01-02", i.CompletionDate)
+	completionTime, err := time.ParseInLocation("2006-01-02", i.CompletionDate, time.Local)
 	if err != nil {
-		return false
+		return 0, false
 	}
 
-	cutoffDate := time.Now().AddDate(0, 0, -days)
-	return completionTime.Before(cutoffDate)
+	return -DaysUntil(completionTime, now), true
 }
 
-// ShouldBeVisible returns true if a todo should be visible in the main view
-// Completed todos older than 5 days should not be visible
-func (i Item) ShouldBeVisible() bool {
+// IsVisibleFor returns true if a todo should be visible in the main view when
+// completed todos are archived after age days
+func (i Item) IsVisibleFor(age int) bool {
 	if !i.Completed {
 		return true
 	}
-	return !i.IsCompletedOlderThanDays(5)
-}
-
-// ArchiveOldCompletedTodos moves completed todos older than 5 days to archive files
-// Returns the remaining todos (without archived items) and any error
-func ArchiveOldCompletedTodos(todos []Item, archiveDir string) ([]Item, error) {
-	// Group old completed todos by month
-	archiveByMonth := make(map[string][]Item)
-	var remainingTodos []Item
-
-	for _, item := range todos {
-		if item.IsCompletedOlderThanDays(5) {
-			// Parse completion date to get year and month
-			if item.CompletionDate != "" {
-				completionTime, err := time.Parse("2006-01-02", item.CompletionDate)
-				if err == nil {
-					monthKey := completionTime.Format("2006_01")
-					archiveByMonth[monthKey] = append(archiveByMonth[monthKey], item)
-					continue
-				}
-			}
-			// If we can't parse the date, keep it in the main list
-			remainingTodos = append(remainingTodos, item)
-		} else {
-			remainingTodos = append(remainingTodos, item)
-		}
-	}
-
-	// Write each month's items to the appropriate archive file
-	for monthKey, items := range archiveByMonth {
-		archiveFilename := fmt.Sprintf("%s/todo_archive_%s.txt", archiveDir, monthKey)
-
-		// Open file in append mode, create if doesn't exist
-		file, err := os.OpenFile(archiveFilename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-		if err != nil {
-			return nil, fmt.Errorf("failed to open archive file %s: %w", archiveFilename, err)
-		}
-
-		writer := bufio.NewWriter(file)
-		for _, item := range items {
-			_, err := fmt.Fprintln(writer, item.String())
-			if err != nil {
-				_ = file.Close() // Best effort close on error path
-				return nil, fmt.Errorf("failed to write to archive file %s: %w", archiveFilename, err)
-			}
-		}
-
-		if err := writer.Flush(); err != nil {
-			_ = file.Close() // Best effort close on error path
-			return nil, fmt.Errorf("failed to flush archive file %s: %w", archiveFilename, err)
-		}
-
-		if err := file.Close(); err != nil {
-			return nil, fmt.Errorf("failed to close archive file %s: %w", archiveFilename, err)
-		}
-	}
-
-	return remainingTodos, nil
+	return !i.IsCompletedOlderThanDays(age)
 }
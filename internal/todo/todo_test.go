@@ -1,8 +1,10 @@
 package todo
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -98,7 +100,38 @@ func TestIsCompletedOlderThanDays(t *testing.T) {
 	}
 }
 
-func TestShouldBeVisible(t *testing.T) {
+func TestCompletedDaysAgo(t *testing.T) {
+	// Days are counted in the time zone of now, not in UTC
+	ahead := time.FixedZone("UTC+10", 10*60*60)
+	behind := time.FixedZone("UTC-10", -10*60*60)
+
+	tests := []struct {
+		name   string
+		line   string
+		now    time.Time
+		want   int
+		wantOK bool
+	}{
+		{name: "today", line: "x 2025-10-17 Task", now: time.Date(2025, 10, 17, 12, 0, 0, 0, time.UTC), want: 0, wantOK: true},
+		{name: "today, ahead of UTC", line: "x 2025-10-17 Task", now: time.Date(2025, 10, 17, 0, 30, 0, 0, ahead), want: 0, wantOK: true},
+		{name: "today, behind UTC", line: "x 2025-10-17 Task", now: time.Date(2025, 10, 17, 23, 30, 0, 0, behind), want: 0, wantOK: true},
+		{name: "yesterday just after midnight", line: "x 2025-10-16 Task", now: time.Date(2025, 10, 17, 0, 1, 0, 0, ahead), want: 1, wantOK: true},
+		{name: "across a month", line: "x 2025-09-28 Task", now: time.Date(2025, 10, 3, 8, 0, 0, 0, behind), want: 5, wantOK: true},
+		{name: "open task", line: "2025-10-01 Task", now: time.Date(2025, 10, 17, 12, 0, 0, 0, time.UTC)},
+		{name: "no completion date", line: "x Task", now: time.Date(2025, 10, 17, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Parse(tt.line).completedDaysAgo(tt.now)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("completedDaysAgo(%v) = %d, %v, want %d, %v", tt.now, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestIsVisibleFor(t *testing.T) {
 	tests := []struct {
 		name            string
 		item            Item
@@ -147,9 +180,9 @@ func TestShouldBeVisible(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := tt.item.ShouldBeVisible()
+			result := tt.item.IsVisibleFor(DefaultArchiveAge)
 			if result != tt.expectedVisible {
-				t.Errorf("ShouldBeVisible() = %v, want %v", result, tt.expectedVisible)
+				t.Errorf("IsVisibleFor(%d) = %v, want %v", DefaultArchiveAge, result, tt.expectedVisible)
 			}
 		})
 	}
@@ -383,19 +416,89 @@ func TestString(t *testing.T) {
 		expected string
 	}{
 		{
-			name: "simple item returns raw string",
+			name: "simple item",
 			item: Item{
-				Raw: "Buy groceries @Store",
+				Description: "Buy groceries @Store",
+				Contexts:    []string{"Store"},
 			},
 			expected: "Buy groceries @Store",
 		},
 		{
-			name: "completed item returns raw string",
+			name: "completed item",
 			item: Item{
-				Raw: "x 2025-09-25 Complete task",
+				Completed:      true,
+				CompletionDate: "2025-09-25",
+				Description:    "Complete task",
 			},
 			expected: "x 2025-09-25 Complete task",
 		},
+		{
+			name: "priority and creation date",
+			item: Item{
+				Priority:     "A",
+				CreationDate: "2025-09-26",
+				Description:  "Call dentist",
+			},
+			expected: "(A) 2025-09-26 Call dentist",
+		},
+		{
+			name: "completed item keeps priority as pri tag",
+			item: Item{
+				Completed:      true,
+				CompletionDate: "2025-09-27",
+				CreationDate:   "2025-09-26",
+				Priority:       "A",
+				Description:    "Call dentist @Phone",
+				Contexts:       []string{"Phone"},
+			},
+			expected: "x 2025-09-27 2025-09-26 Call dentist @Phone pri:A",
+		},
+		{
+			name: "existing pri tag is rewritten in place",
+			item: Item{
+				Priority:    "C",
+				Description: "Learn Go pri:B @Learning",
+				Contexts:    []string{"Learning"},
+			},
+			expected: "Learn Go pri:C @Learning",
+		},
+		{
+			name: "cleared priority drops pri tag",
+			item: Item{
+				Description: "Learn Go pri:B",
+			},
+			expected: "Learn Go",
+		},
+		{
+			name: "added contexts and projects are appended",
+			item: Item{
+				Description: "Review code @Work",
+				Contexts:    []string{"Work", "Office"},
+				Projects:    []string{"ProjectX"},
+			},
+			expected: "Review code @Work @Office +ProjectX",
+		},
+		{
+			name: "removed contexts and projects are dropped, order kept",
+			item: Item{
+				Description: "Review @Work code +ProjectX @Office",
+				Contexts:    []string{"Office"},
+				Projects:    []string{},
+			},
+			expected: "Review code @Office",
+		},
+		{
+			name: "unknown tokens are kept",
+			item: Item{
+				Description: "Read https://example.com ~later #42",
+			},
+			expected: "Read https://example.com ~later #42",
+		},
+		{
+			name:     "empty item",
+			item:     Item{},
+			expected: "",
+		},
 	}
 
 	for _, tt := range tests {
@@ -408,6 +511,124 @@ func TestString(t *testing.T) {
 	}
 }
 
+func TestString_RoundTrip(t *testing.T) {
+	lines := []string{
+		"Buy groceries",
+		"(A) Call dentist",
+		"(B) 2025-09-26 Finish report",
+		"Team meeting @Work @Office",
+		"Review code @Work +ProjectX",
+		"x Buy milk",
+		"x 2025-09-25 2025-09-24 Review blog post",
+		"x 2025-09-20 2025-09-18 Setup development environment @Work +DevOps pri:B",
+		"Learn Go concurrency patterns @Learning +Programming pri:B",
+		"Duplicate @ctx tokens @ctx and pri:A twice pri:C",
+		"(A) x marks the spot",
+		"(A) 2025-09-26 2025-09-27 Description starting with a date",
+		"Read https://example.com later",
+		"x",
+		"(A)",
+		"",
+	}
+
+	for _, line := range lines {
+		t.Run(line, func(t *testing.T) {
+			item := Parse(line)
+			if got := item.String(); got != line {
+				t.Errorf("Parse(%q).String() = %q", line, got)
+			}
+			if reparsed := Parse(item.String()); !reflect.DeepEqual(reparsed, item) {
+				t.Errorf("Parse(item.String()) = %+v, want %+v", reparsed, item)
+			}
+		})
+	}
+}
+
+func TestString_KeepsUntouchedLines(t *testing.T) {
+	lines := []string{
+		"Buy  milk\twith tabs",
+		"(B) foo pri:A",
+		"  indented task @home",
+		"x  2025-09-25   done late",
+		"due:2025-10-01 due:2025-10-02 Two due tags",
+	}
+
+	for _, line := range lines {
+		t.Run(line, func(t *testing.T) {
+			item := Parse(line)
+			if got := item.String(); got != line {
+				t.Errorf("Parse(%q).String() = %q, want the line unchanged", line, got)
+			}
+			if reparsed := Parse(item.String()); !reflect.DeepEqual(reparsed, item) {
+				t.Errorf("Parse(item.String()) = %+v, want %+v", reparsed, item)
+			}
+		})
+	}
+
+	// Changed items are rebuilt from their fields
+	item := Parse("Buy  milk\twith tabs")
+	item.Priority = "A"
+	if got := item.String(); got != "(A) Buy milk with tabs" {
+		t.Errorf("String() of a changed item = %q", got)
+	}
+
+	// Changes made in place to the contexts, projects or tags count as well
+	item = Parse("Call  mom @home due:2025-10-01")
+	item.Contexts[0] = "phone"
+	item.Tags["due"] = "2025-10-02"
+	if got := item.String(); got != "Call mom due:2025-10-02 @phone" {
+		t.Errorf("String() of an item changed in place = %q", got)
+	}
+}
+
+func TestSaveToFile_KeepsUntouchedLines(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "todo.txt")
+	content := "Buy  milk\twith tabs\n(B) foo pri:A\n  indented task @home\n"
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	todos, err := LoadFromFile(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+	todos = append(todos, Parse("new"))
+	todos = Complete(todos, 2, time.Date(2025, 10, 1, 0, 0, 0, 0, time.Local))
+	if err := SaveToFile(tmpFile, todos); err != nil {
+		t.Fatalf("SaveToFile() error = %v", err)
+	}
+
+	got, _ := os.ReadFile(tmpFile)
+	want := "Buy  milk\twith tabs\n(B) foo pri:A\nx 2025-10-01 indented task @home\nnew\n"
+	if string(got) != want {
+		t.Errorf("File after save = %q, want %q", got, want)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	item := Parse("(A) 2025-09-26 Call dentist @Phone +Health")
+	item.Completed = true
+	item.CompletionDate = "2025-09-27"
+	item.Contexts = []string{"Home"}
+	item.Projects = nil
+
+	normalized := item.Normalize()
+
+	expectedRaw := "x 2025-09-27 2025-09-26 Call dentist @Home pri:A"
+	if normalized.Raw != expectedRaw {
+		t.Errorf("Raw = %q, want %q", normalized.Raw, expectedRaw)
+	}
+	if normalized.Description != "Call dentist @Home pri:A" {
+		t.Errorf("Description = %q, want %q", normalized.Description, "Call dentist @Home pri:A")
+	}
+	if normalized.Priority != "A" {
+		t.Errorf("Priority = %q, want %q", normalized.Priority, "A")
+	}
+	if !reflect.DeepEqual(Parse(normalized.String()), normalized) {
+		t.Errorf("Normalize() result does not round-trip: %+v", normalized)
+	}
+}
+
 func TestLoadFromFile(t *testing.T) {
 	// Create a temporary file with test data
 	tmpDir := t.TempDir()
@@ -464,9 +685,9 @@ func TestSaveToFile(t *testing.T) {
 	tmpFile := filepath.Join(tmpDir, "test_save.txt")
 
 	items := []Item{
-		{Raw: "(A) Task one @Work"},
-		{Raw: "x 2025-09-25 Task two"},
-		{Raw: "Task three +Project"},
+		Parse("(A) Task one @Work"),
+		Parse("x 2025-09-25 Task two"),
+		Parse("Task three +Project"),
 	}
 
 	err := SaveToFile(tmpFile, items)
@@ -497,14 +718,14 @@ func TestSaveToFile(t *testing.T) {
 }
 
 func TestSaveToFile_InvalidPath(t *testing.T) {
-	items := []Item{{Raw: "Test"}}
+	items := []Item{Parse("Test")}
 	err := SaveToFile("/nonexistent/dir/file.txt", items)
 	if err == nil {
 		t.Error("SaveToFile() should return error for invalid path")
 	}
 }
 
-func TestArchiveOldCompletedTodos(t *testing.T) {
+func TestArchive_Archive(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	// Create test items
@@ -532,14 +753,14 @@ func TestArchiveOldCompletedTodos(t *testing.T) {
 	}
 
 	// Archive old items
-	remaining, err := ArchiveOldCompletedTodos(items, tmpDir)
+	remaining, _, err := DefaultArchive(tmpDir).Archive(items)
 	if err != nil {
-		t.Fatalf("ArchiveOldCompletedTodos() error = %v", err)
+		t.Fatalf("Archive() error = %v", err)
 	}
 
 	// Should have 2 remaining items (recent completed + active)
 	if len(remaining) != 2 {
-		t.Errorf("ArchiveOldCompletedTodos() returned %d items, want 2", len(remaining))
+		t.Errorf("Archive() returned %d items, want 2", len(remaining))
 	}
 
 	// Verify the old item was archived
@@ -572,7 +793,7 @@ func TestArchiveOldCompletedTodos(t *testing.T) {
 	}
 }
 
-func TestArchiveOldCompletedTodos_NoOldItems(t *testing.T) {
+func TestArchive_ArchiveNoOldItems(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	items := []Item{
@@ -582,13 +803,13 @@ func TestArchiveOldCompletedTodos_NoOldItems(t *testing.T) {
 		},
 	}
 
-	remaining, err := ArchiveOldCompletedTodos(items, tmpDir)
+	remaining, _, err := DefaultArchive(tmpDir).Archive(items)
 	if err != nil {
-		t.Fatalf("ArchiveOldCompletedTodos() error = %v", err)
+		t.Fatalf("Archive() error = %v", err)
 	}
 
 	if len(remaining) != 1 {
-		t.Errorf("ArchiveOldCompletedTodos() returned %d items, want 1", len(remaining))
+		t.Errorf("Archive() returned %d items, want 1", len(remaining))
 	}
 
 	// No archive files should be created
@@ -601,3 +822,280 @@ func TestArchiveOldCompletedTodos_NoOldItems(t *testing.T) {
 		t.Errorf("Expected no files in archive dir, found %d", len(files))
 	}
 }
+
+func TestItemJSON(t *testing.T) {
+	item := Parse("x 2025-09-25 2025-09-24 Review blog post @Work +Blog due:2025-09-30 pri:A")
+
+	data, err := json.Marshal(item)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	expected := map[string]any{
+		"completed":       true,
+		"priority":        "A",
+		"completion_date": "2025-09-25",
+		"creation_date":   "2025-09-24",
+		"description":     "Review blog post @Work +Blog due:2025-09-30 pri:A",
+		"contexts":        []any{"Work"},
+		"projects":        []any{"Blog"},
+		"tags":            map[string]any{"due": "2025-09-30"},
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("JSON = %s", data)
+	}
+}
+
+func TestArchive_Remove(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	oldDate := time.Now().AddDate(0, 0, -10).Format("2006-01-02")
+	archiveFile := filepath.Join(tmpDir, "todo_archive_"+time.Now().AddDate(0, 0, -10).Format("2006_01")+".txt")
+	if err := os.WriteFile(archiveFile, []byte("x "+oldDate+" Archived earlier\n"), 0644); err != nil {
+		t.Fatalf("Failed to create archive file: %v", err)
+	}
+
+	items := []Item{
+		Parse("x " + oldDate + " Old task @Work"),
+		Parse("Active task"),
+	}
+
+	archive := DefaultArchive(tmpDir)
+	remaining, archived, err := archive.Archive(items)
+	if err != nil {
+		t.Fatalf("Archive() error = %v", err)
+	}
+	if len(remaining) != 1 || len(archived) != 1 {
+		t.Fatalf("Archive() = %d remaining, %d archived, want 1 and 1", len(remaining), len(archived))
+	}
+
+	if err := archive.Remove(archived); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+
+	content, err := os.ReadFile(archiveFile)
+	if err != nil {
+		t.Fatalf("Failed to read archive file: %v", err)
+	}
+	if string(content) != "x "+oldDate+" Archived earlier\n" {
+		t.Errorf("Archive content = %q, want only the earlier item", content)
+	}
+
+	// Removing the last item deletes the archive file
+	if err := archive.Remove([]Item{Parse("x " + oldDate + " Archived earlier")}); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := os.Stat(archiveFile); !os.IsNotExist(err) {
+		t.Error("Empty archive file should be removed")
+	}
+}
+
+func TestArchive_Layouts(t *testing.T) {
+	dir := "/todo"
+	item := Parse("x 2025-03-14 Ship it +Tada/CLI @Work")
+	noProject := Parse("x 2025-03-14 Call mom")
+	noDate := Parse("x Undated task")
+
+	tests := []struct {
+		layout   ArchiveLayout
+		item     Item
+		expected string
+	}{
+		{LayoutMonthly, item, "todo_archive_2025_03.txt"},
+		{LayoutYearly, item, "todo_archive_2025.txt"},
+		{LayoutSingle, item, "done.txt"},
+		{LayoutSingle, noDate, "done.txt"},
+		{LayoutProject, item, "todo_archive_project_Tada_CLI.txt"},
+		{LayoutProject, noProject, "todo_archive_no_project.txt"},
+		{LayoutMonthly, noDate, ""},
+		{LayoutYearly, noDate, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.layout)+" "+tt.item.Description, func(t *testing.T) {
+			archive := Archive{Dir: dir, Layout: tt.layout}
+			got, ok := archive.Filename(tt.item)
+			if tt.expected == "" {
+				if ok {
+					t.Errorf("Filename() = %q, want none", got)
+				}
+				return
+			}
+			if !ok || got != filepath.Join(dir, tt.expected) {
+				t.Errorf("Filename() = %q, %v, want %q", got, ok, tt.expected)
+			}
+		})
+	}
+
+	if _, err := ParseArchiveLayout("weekly"); err == nil {
+		t.Error("ParseArchiveLayout() should reject unknown layouts")
+	}
+	if layout, err := ParseArchiveLayout(""); err != nil || layout != LayoutMonthly {
+		t.Errorf("ParseArchiveLayout(\"\") = %q, %v, want monthly", layout, err)
+	}
+}
+
+func TestArchive_AgeZero(t *testing.T) {
+	tmpDir := t.TempDir()
+	today := time.Now().Format("2006-01-02")
+
+	items := []Item{
+		Parse("x " + today + " Done today"),
+		Parse("Active task"),
+	}
+
+	archive := Archive{Dir: tmpDir, Age: 0, Layout: LayoutSingle}
+	remaining, archived, err := archive.Archive(items)
+	if err != nil {
+		t.Fatalf("Archive() error = %v", err)
+	}
+	if len(remaining) != 1 || len(archived) != 1 {
+		t.Fatalf("Archive() = %d remaining, %d archived, want 1 and 1", len(remaining), len(archived))
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "done.txt"))
+	if err != nil {
+		t.Fatalf("Failed to read done.txt: %v", err)
+	}
+	if string(content) != "x "+today+" Done today\n" {
+		t.Errorf("done.txt = %q", content)
+	}
+
+	// The default age keeps the task
+	_, archived, err = DefaultArchive(tmpDir).Archive(items)
+	if err != nil || len(archived) != 0 {
+		t.Errorf("DefaultArchive().Archive() archived %d tasks, err %v, want none", len(archived), err)
+	}
+}
+
+func TestArchive_RemoveAfterLayoutChange(t *testing.T) {
+	tmpDir := t.TempDir()
+	items := []Item{Parse("x 2024-06-01 Old task +Home")}
+
+	// Archived with the monthly layout, restored with the yearly one
+	if err := DefaultArchive(tmpDir).Append(items); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	yearly := Archive{Dir: tmpDir, Layout: LayoutYearly}
+	if err := yearly.Remove(items); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+
+	files, err := ArchiveFiles(tmpDir)
+	if err != nil {
+		t.Fatalf("ArchiveFiles() error = %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("Expected the monthly archive file to be removed, got %v", files)
+	}
+}
+
+func TestRestoreArchived(t *testing.T) {
+	dir := t.TempDir()
+	tmpFile := filepath.Join(dir, "todo.txt")
+	if err := os.WriteFile(tmpFile, []byte("Open task\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "done.txt"), []byte("x 2024-03-02 Fix fence\n"), 0644); err != nil {
+		t.Fatalf("Failed to create done.txt: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "todo_archive_2025_01.txt"), []byte("x 2025-01-09 (B) 2025-01-02 Call bank\n\n"), 0644); err != nil {
+		t.Fatalf("Failed to create archive file: %v", err)
+	}
+
+	archived, err := LoadArchives(dir)
+	if err != nil {
+		t.Fatalf("LoadArchives() error = %v", err)
+	}
+	if len(archived) != 2 || archived[0].Description != "Fix fence" || filepath.Base(archived[1].File) != "todo_archive_2025_01.txt" {
+		t.Fatalf("LoadArchives() = %+v", archived)
+	}
+
+	entry, err := RestoreArchived(tmpFile, DefaultArchive(dir), []Item{archived[1].Item})
+	if err != nil {
+		t.Fatalf("RestoreArchived() error = %v", err)
+	}
+	if entry.Op != OpRestore || len(entry.Restored) != 1 {
+		t.Errorf("Unexpected journal entry %+v", entry)
+	}
+	content, _ := os.ReadFile(tmpFile)
+	if string(content) != "Open task\n(B) 2025-01-02 Call bank\n" {
+		t.Errorf("File after restore = %q", content)
+	}
+
+	// A task that is not archived any more can't be restored twice
+	if _, err := RestoreArchived(tmpFile, DefaultArchive(dir), []Item{archived[1].Item, archived[0].Item}); err == nil {
+		t.Error("Restoring a task that is not archived should fail")
+	}
+	if archived, _ := LoadArchives(dir); len(archived) != 1 {
+		t.Errorf("Expected a failed restore to leave the archive alone, got %+v", archived)
+	}
+}
+
+func TestRestoreArchivedIDs(t *testing.T) {
+	dir := t.TempDir()
+	tmpFile := filepath.Join(dir, "todo.txt")
+	if err := os.WriteFile(tmpFile, []byte("Open task\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "done.txt"), []byte("x 2024-03-02 Fix fence\n"), 0644); err != nil {
+		t.Fatalf("Failed to create done.txt: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "todo_archive_2025_01.txt"), []byte("x 2025-01-09 Call bank\n\nx 2025-01-10 Pay rent\n"), 0644); err != nil {
+		t.Fatalf("Failed to create archive file: %v", err)
+	}
+
+	// Numbers outside the archive change nothing
+	for _, ids := range [][]int{{0}, {2, 4}} {
+		if _, err := RestoreArchivedIDs(tmpFile, DefaultArchive(dir), ids); err == nil {
+			t.Errorf("RestoreArchivedIDs(%v) should fail", ids)
+		}
+	}
+	if content, _ := os.ReadFile(tmpFile); string(content) != "Open task\n" {
+		t.Errorf("File after failed restore = %q", content)
+	}
+
+	// Numbers skip blank lines like tada archive ls, duplicates count once
+	if _, err := RestoreArchivedIDs(tmpFile, DefaultArchive(dir), []int{3, 1, 3}); err != nil {
+		t.Fatalf("RestoreArchivedIDs() error = %v", err)
+	}
+	if content, _ := os.ReadFile(tmpFile); string(content) != "Open task\nPay rent\nFix fence\n" {
+		t.Errorf("File after restore = %q", content)
+	}
+	if archived, _ := LoadArchives(dir); len(archived) != 1 || archived[0].Description != "Call bank" {
+		t.Errorf("Archive after restore = %+v", archived)
+	}
+}
+
+func TestRestoreArchived_SaveFailureKeepsArchive(t *testing.T) {
+	// Files in /proc can be read but no file can be created next to them,
+	// not even by root, so saving todo.txt fails
+	const readOnly = "/proc/version"
+	if _, err := os.Stat(readOnly); err != nil {
+		t.Skipf("%s not available: %v", readOnly, err)
+	}
+	dir := t.TempDir()
+	tmpFile := filepath.Join(dir, "todo.txt")
+	if err := os.Symlink(readOnly, tmpFile); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	archiveFile := filepath.Join(dir, "todo_archive_2025_01.txt")
+	if err := os.WriteFile(archiveFile, []byte("x 2025-01-09 Call bank\n"), 0644); err != nil {
+		t.Fatalf("Failed to create archive file: %v", err)
+	}
+
+	if _, err := RestoreArchived(tmpFile, DefaultArchive(dir), []Item{Parse("x 2025-01-09 Call bank")}); err == nil {
+		t.Fatal("RestoreArchived() should fail when todo.txt can't be saved")
+	}
+	if got, _ := os.ReadFile(archiveFile); string(got) != "x 2025-01-09 Call bank\n" {
+		t.Errorf("Archive after failed restore = %q, want it unchanged", got)
+	}
+	if entries, _ := LoadJournal(tmpFile); len(entries) != 0 {
+		t.Errorf("Expected the failed restore not to be recorded, got %+v", entries)
+	}
+}
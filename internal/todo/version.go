@@ -0,0 +1,27 @@
+package todo
+
+import (
+	"os"
+	"time"
+)
+
+// FileVersion identifies the state of a file on disk
+// Two versions differ when the file was written in between.
+type FileVersion struct {
+	ModTime time.Time
+	Size    int64
+}
+
+// StatFile returns the current version of a file
+func StatFile(filename string) (FileVersion, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return FileVersion{}, err
+	}
+	return FileVersion{ModTime: info.ModTime(), Size: info.Size()}, nil
+}
+
+// Equal reports whether both versions describe the same file state
+func (v FileVersion) Equal(other FileVersion) bool {
+	return v.ModTime.Equal(other.ModTime) && v.Size == other.Size
+}
@@ -0,0 +1,260 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"tada/internal/todo"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// errStillArchived is returned when todo.txt was saved, but restored todos
+// could not be taken out of the archive files
+var errStillArchived = errors.New("still in the archive")
+
+// browseActions are the normal mode actions that also work in the archive
+// browser, the others would act on todo.txt
+var browseActions = []Action{
+	ActionUp, ActionDown, ActionPrevList, ActionNextList,
+	ActionHalfPageDown, ActionHalfPageUp, ActionTop, ActionBottom,
+	ActionSearch, ActionSearchNext, ActionSearchPrev,
+}
+
+// openArchive shows the archived todos matching query instead of todo.txt
+func (m Model) openArchive(query string) (Model, tea.Cmd) {
+	q, err := todo.ParseQuery(query)
+	if err != nil {
+		// Stay in command mode so the query can be fixed
+		cmd := m.setError(err)
+		return m, cmd
+	}
+	archived, err := todo.LoadArchives(m.archive.Dir)
+	if err != nil {
+		cmd := m.setError(fmt.Errorf("can't load the archive: %w", err))
+		return m, cmd
+	}
+
+	// Return to the archive browser instead of normal mode
+	m.commandInput.Blur()
+	m.mode = ModeArchive
+	m.browsing = true
+	m.archived = archived
+	m.archiveQuery = q
+	m.archiveMarks = make(map[int]bool)
+	m.browseOrigin = position{list: m.listCursor, item: m.itemCursor}
+	m.listCursor, m.itemCursor = 0, 0
+	m.refreshGroups()
+
+	return m, nil
+}
+
+// closeArchive goes back from the archive browser to the todo lists
+func (m *Model) closeArchive() {
+	m.mode = ModeNormal
+	m.browsing = false
+	m.archived = nil
+	m.archiveMarks = nil
+	m.listCursor, m.itemCursor = m.browseOrigin.list, m.browseOrigin.item
+	m.refreshGroups()
+}
+
+// baseMode returns the mode that prompts return to: the archive browser
+// while it is open, normal mode otherwise
+func (m Model) baseMode() Mode {
+	if m.browsing {
+		return ModeArchive
+	}
+	return ModeNormal
+}
+
+// archiveGroups groups the archived todos matching the query by archive file
+// Indexes of the items refer to m.archived.
+func (m Model) archiveGroups() []todo.Group {
+	var groups []todo.Group
+	for idx, item := range m.archived {
+		if !m.archiveQuery.Match(item.Item) {
+			continue
+		}
+		if len(groups) == 0 || groups[len(groups)-1].Name != item.File {
+			groups = append(groups, todo.Group{Name: item.File, Title: filepath.Base(item.File)})
+		}
+		group := &groups[len(groups)-1]
+		group.Todos = append(group.Todos, todo.IndexedItem{Item: item.Item, Index: idx})
+	}
+	return groups
+}
+
+// currentArchived returns the index in m.archived of the todo under the
+// cursor, -1 if there is none
+func (m Model) currentArchived() int {
+	if m.listCursor >= len(m.groups) || m.itemCursor >= len(m.groups[m.listCursor].Todos) {
+		return -1
+	}
+	return m.groups[m.listCursor].Todos[m.itemCursor].Index
+}
+
+// handleArchiveMode handles key presses in the archive browser
+func (m Model) handleArchiveMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if len(m.pendingKeys) == 0 {
+		switch msg.String() {
+		case "enter", "r":
+			return m.restoreArchived()
+		case "m":
+			// Toggle the mark and move on, to mark several tasks quickly
+			if idx := m.currentArchived(); idx != -1 {
+				m.archiveMarks[idx] = !m.archiveMarks[idx]
+				m.moveCursor(1)
+			}
+			return m, nil
+		case "esc", "q":
+			m.closeArchive()
+			return m, nil
+		}
+	}
+
+	// Navigation and search keys are the ones of normal mode
+	keys := append(slices.Clone(m.pendingKeys), msg.String())
+	action, pending := m.keymap.Lookup(keys)
+	if pending {
+		m.pendingKeys = keys
+		return m, nil
+	}
+	m.pendingKeys = nil
+	if !slices.Contains(browseActions, action) {
+		return m, nil
+	}
+	return m.runAction(action)
+}
+
+// restoreArchived moves the marked todos, or the one under the cursor if none
+// is marked, back to the end of todo.txt as open tasks
+func (m Model) restoreArchived() (tea.Model, tea.Cmd) {
+	marked := m.markedArchived()
+	if len(marked) == 0 {
+		idx := m.currentArchived()
+		if idx == -1 {
+			return m, nil
+		}
+		marked = []int{idx}
+	}
+	items := make([]todo.Item, len(marked))
+	for i, idx := range marked {
+		items[i] = m.archived[idx].Item
+	}
+
+	// Nothing changes if a task is not in the archive any more
+	if err := m.archive.Check(items); err != nil {
+		cmd := m.setError(fmt.Errorf("restore failed: %w", err))
+		return m, cmd
+	}
+
+	// todo.txt is saved before the tasks leave the archive, a failed save
+	// puts everything back as it was and the tasks stay archived
+	sp := m.savepoint()
+	for _, item := range items {
+		m.todos = append(m.todos, todo.Reopen(item))
+	}
+	m.recordRestore(sp.todos, items)
+	err := m.saveMoving(sp, nil, items)
+	if err != nil && !errors.Is(err, todo.ErrJournal) && !errors.Is(err, errStillArchived) {
+		cmd := m.setError(fmt.Errorf("restore failed: %w", err))
+		return m, cmd
+	}
+
+	// The browser shows what is left in the archive
+	if archived, err := todo.LoadArchives(m.archive.Dir); err == nil {
+		m.archived = archived
+	}
+	m.archiveMarks = make(map[int]bool)
+	m.refreshGroups()
+
+	if errors.Is(err, errStillArchived) {
+		cmd := m.setError(fmt.Errorf("restored, but %w", err))
+		return m, cmd
+	}
+	if err != nil {
+		cmd := m.saveFailed(err)
+		return m, cmd
+	}
+	cmd := m.setStatus("restored %s", tasks(len(items)))
+	return m, cmd
+}
+
+// savepoint is the state of the model before a change that moves todos
+// between todo.txt and the archive files
+type savepoint struct {
+	todos          []string
+	history        history
+	pendingJournal []todo.JournalEntry
+	dirty          bool
+}
+
+// savepoint returns the current state of the model
+func (m Model) savepoint() savepoint {
+	return savepoint{
+		todos:          m.snapshot(),
+		history:        m.history,
+		pendingJournal: m.pendingJournal,
+		dirty:          m.dirty,
+	}
+}
+
+// rollback puts the model back to the state of sp
+func (m *Model) rollback(sp savepoint) {
+	m.restore(sp.todos)
+	m.history, m.pendingJournal, m.dirty = sp.history, sp.pendingJournal, sp.dirty
+	m.refreshGroups()
+}
+
+// saveMoving saves the todos while moving todos between todo.txt and the
+// archive files, in the order the command line uses: archived todos are
+// appended first and restored ones taken out last, so a failure leaves a
+// task in both places rather than in none. If appending or the save fails,
+// the archive files are left as they were and the model goes back to sp.
+func (m *Model) saveMoving(sp savepoint, archived, restored []todo.Item) error {
+	if len(archived) == 0 && len(restored) == 0 {
+		return m.save()
+	}
+
+	err := m.saveMoved(archived, restored)
+	if err != nil && !errors.Is(err, todo.ErrJournal) && !errors.Is(err, errStillArchived) {
+		m.rollback(sp)
+	}
+	return err
+}
+
+// archiveHint describes the archive browser for the mode line
+func (m Model) archiveHint() string {
+	hint := fmt.Sprintf("  %s archived", tasks(len(m.archived)))
+	if !m.archiveQuery.IsEmpty() {
+		hint += ", query: " + m.archiveQuery.String()
+	}
+	if marked := len(m.markedArchived()); marked > 0 {
+		hint += fmt.Sprintf(", %d marked", marked)
+	}
+	return hint
+}
+
+// markedArchived returns the indexes in m.archived of the marked todos, in
+// archive order
+func (m Model) markedArchived() []int {
+	var marked []int
+	for idx, isMarked := range m.archiveMarks {
+		if isMarked {
+			marked = append(marked, idx)
+		}
+	}
+	slices.Sort(marked)
+	return marked
+}
+
+// archiveEmptyText is shown in the archive browser when no todo is listed
+func (m Model) archiveEmptyText() string {
+	if len(m.archived) > 0 {
+		return "No archived todos match " + strings.TrimSpace(m.archiveQuery.String()) + ". Press esc to go back."
+	}
+	return "The archive is empty. Press esc to go back."
+}
@@ -0,0 +1,163 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"tada/internal/todo"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxHistory is the number of changes that can be undone
+const maxHistory = 100
+
+var (
+	errNothingToUndo = errors.New("nothing to undo")
+	errNothingToRedo = errors.New("nothing to redo")
+)
+
+// change records the todo list before and after a mutating action
+type change struct {
+	label    string
+	before   []string
+	after    []string
+	archived []todo.Item // Todos the action moved to archive files
+	restored []todo.Item // Todos the action moved out of archive files, as archived
+}
+
+// history holds the changes that can be undone and redone
+type history struct {
+	undo []change
+	redo []change
+}
+
+// snapshot returns the todo list as todo.txt lines
+func (m Model) snapshot() []string {
+	return todo.Lines(m.todos)
+}
+
+// restore replaces the todo list with the given todo.txt lines
+func (m *Model) restore(lines []string) {
+	todos := make([]todo.Item, len(lines))
+	for i, line := range lines {
+		todos[i] = todo.Parse(line)
+	}
+	m.todos = todos
+}
+
+// record adds a change made since the before snapshot to the undo history
+// and to the journal. Any redo history is dropped, like in vim.
+func (m *Model) record(label string, before []string, archived []todo.Item) {
+	m.push(change{
+		label:    label,
+		before:   before,
+		after:    m.snapshot(),
+		archived: archived,
+	})
+}
+
+// recordRestore adds a restore of archived todos made since the before
+// snapshot to the undo history and to the journal
+func (m *Model) recordRestore(before []string, restored []todo.Item) {
+	m.push(change{
+		label:    todo.OpRestore,
+		before:   before,
+		after:    m.snapshot(),
+		restored: restored,
+	})
+}
+
+// push adds a change to the undo history and to the journal
+func (m *Model) push(c change) {
+	m.history.undo = append(m.history.undo, c)
+	if len(m.history.undo) > maxHistory {
+		m.history.undo = m.history.undo[len(m.history.undo)-maxHistory:]
+	}
+	m.history.redo = nil
+
+	entry := todo.NewJournalEntry(c.label, c.before, c.after)
+	entry.Archived = todo.Lines(c.archived)
+	entry.Restored = todo.Lines(c.restored)
+	m.journal(entry)
+}
+
+// journal queues an entry for the journal, it is written with the next save
+func (m *Model) journal(entry todo.JournalEntry) {
+	entry.Time = time.Now()
+	m.pendingJournal = append(m.pendingJournal, entry)
+}
+
+// undo reverts the most recent change and writes the result to disk
+func (m *Model) undo() (string, error) {
+	if len(m.history.undo) == 0 {
+		return "", errNothingToUndo
+	}
+
+	sp := m.savepoint()
+	last := len(m.history.undo) - 1
+	c := m.history.undo[last]
+	m.history.undo = m.history.undo[:last]
+	m.history.redo = append(m.history.redo, c)
+
+	entry := todo.NewJournalEntry(todo.OpUndo, c.after, c.before)
+	entry.Archived = todo.Lines(c.restored)
+	entry.Restored = todo.Lines(c.archived)
+	m.journal(entry)
+
+	// Archived todos move back into the list, restored ones into the archive.
+	// The change stays on the undo stack if the archive files can't be updated.
+	m.restore(c.before)
+	return c.label, m.saveMoving(sp, c.restored, c.archived)
+}
+
+// redo applies the most recently undone change again and writes the result to disk
+func (m *Model) redo() (string, error) {
+	if len(m.history.redo) == 0 {
+		return "", errNothingToRedo
+	}
+
+	sp := m.savepoint()
+	last := len(m.history.redo) - 1
+	c := m.history.redo[last]
+	m.history.redo = m.history.redo[:last]
+	m.history.undo = append(m.history.undo, c)
+
+	entry := todo.NewJournalEntry(todo.OpRedo, c.before, c.after)
+	entry.Archived = todo.Lines(c.archived)
+	entry.Restored = todo.Lines(c.restored)
+	m.journal(entry)
+
+	m.restore(c.after)
+	return c.label, m.saveMoving(sp, c.archived, c.restored)
+}
+
+// undoWithStatus undoes the most recent change and reports how it went
+func (m *Model) undoWithStatus() tea.Cmd {
+	label, err := m.undo()
+	m.refreshGroups()
+	return m.historyStatus("undid", label, err)
+}
+
+// redoWithStatus redoes the most recently undone change and reports how it went
+func (m *Model) redoWithStatus() tea.Cmd {
+	label, err := m.redo()
+	m.refreshGroups()
+	return m.historyStatus("redid", label, err)
+}
+
+// historyStatus reports the outcome of an undo or redo
+func (m *Model) historyStatus(verb, label string, err error) tea.Cmd {
+	switch {
+	case errors.Is(err, errNothingToUndo), errors.Is(err, errNothingToRedo):
+		return m.setStatus("%s", err)
+	case errors.Is(err, errStillArchived):
+		return m.setError(fmt.Errorf("%s %s, but %w", verb, label, err))
+	case err != nil && m.dirty:
+		return m.saveFailed(err)
+	case err != nil:
+		return m.setError(err)
+	}
+	return m.setStatus("%s %s", verb, label)
+}
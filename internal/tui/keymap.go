@@ -0,0 +1,377 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Action is something a key sequence does in normal mode
+type Action string
+
+// Actions that keys can be bound to
+const (
+	ActionInsert       Action = "insert"
+	ActionCommand      Action = "command"
+	ActionVisual       Action = "visual"
+	ActionDismiss      Action = "dismiss"
+	ActionUp           Action = "up"
+	ActionDown         Action = "down"
+	ActionPrevList     Action = "prev_list"
+	ActionNextList     Action = "next_list"
+	ActionHalfPageDown Action = "half_page_down"
+	ActionHalfPageUp   Action = "half_page_up"
+	ActionTop          Action = "top"
+	ActionBottom       Action = "bottom"
+	ActionToggleFuture Action = "toggle_future"
+	ActionQuit         Action = "quit"
+	ActionSearch       Action = "search"
+	ActionSearchNext   Action = "search_next"
+	ActionSearchPrev   Action = "search_prev"
+	ActionUndo         Action = "undo"
+	ActionRedo         Action = "redo"
+	ActionEdit         Action = "edit"
+	ActionAdd          Action = "add"
+	ActionComplete     Action = "complete"
+	ActionDelete       Action = "delete"
+	ActionSort         Action = "sort"
+
+	// ActionNone unbinds a key sequence in the config file
+	ActionNone Action = "none"
+)
+
+// actionInfo describes an action for the help text
+type actionInfo struct {
+	action      Action
+	group       string
+	description string
+}
+
+// actions lists every action in the order the help text shows them
+var actions = []actionInfo{
+	{ActionInsert, "Modes", "insert"},
+	{ActionCommand, "Modes", "command"},
+	{ActionVisual, "Modes", "visual"},
+	{ActionDismiss, "Modes", "dismiss message"},
+	{ActionDown, "Navigation", "down"},
+	{ActionUp, "Navigation", "up"},
+	{ActionPrevList, "Navigation", "prev list"},
+	{ActionNextList, "Navigation", "next list"},
+	{ActionHalfPageDown, "Navigation", "half page down"},
+	{ActionHalfPageUp, "Navigation", "half page up"},
+	{ActionTop, "Navigation", "top"},
+	{ActionBottom, "Navigation", "bottom"},
+	{ActionToggleFuture, "Navigation", "show/hide future tasks"},
+	{ActionQuit, "Navigation", "quit"},
+	{ActionSearch, "Search", "search"},
+	{ActionSearchNext, "Search", "next match"},
+	{ActionSearchPrev, "Search", "previous match"},
+	{ActionUndo, "History", "undo"},
+	{ActionRedo, "History", "redo"},
+	{ActionEdit, "Tasks", "edit"},
+	{ActionAdd, "Tasks", "add"},
+	{ActionComplete, "Tasks", "complete"},
+	{ActionDelete, "Tasks", "delete"},
+	{ActionSort, "Tasks", "sort"},
+}
+
+// defaultBindings are the normal mode key sequences, in vim-like notation
+var defaultBindings = []struct {
+	seq    string
+	action Action
+}{
+	{"i", ActionInsert},
+	{"<enter>", ActionInsert},
+	{":", ActionCommand},
+	{"v", ActionVisual},
+	{"<esc>", ActionDismiss},
+	{"j", ActionDown},
+	{"<down>", ActionDown},
+	{"k", ActionUp},
+	{"<up>", ActionUp},
+	{"h", ActionPrevList},
+	{"<left>", ActionPrevList},
+	{"l", ActionNextList},
+	{"<right>", ActionNextList},
+	{"<C-d>", ActionHalfPageDown},
+	{"<C-u>", ActionHalfPageUp},
+	{"gg", ActionTop},
+	{"G", ActionBottom},
+	{"F", ActionToggleFuture},
+	{"q", ActionQuit},
+	{"/", ActionSearch},
+	{"n", ActionSearchNext},
+	{"N", ActionSearchPrev},
+	{"u", ActionUndo},
+	{"<C-r>", ActionRedo},
+	{"<leader>e", ActionEdit},
+	{"<leader>a", ActionAdd},
+	{"<leader>n", ActionAdd},
+	{"<leader>c", ActionComplete},
+	{"<leader>d", ActionComplete},
+	{"<leader>r", ActionDelete},
+	{"<leader>x", ActionDelete},
+	{"<leader>s", ActionSort},
+}
+
+// DefaultLeader is the leader key unless the config sets another one
+const DefaultLeader = "<space>"
+
+// binding maps a sequence of keys, as reported by tea.KeyMsg.String(), to an
+// action
+type binding struct {
+	keys   []string
+	action Action
+}
+
+// Keymap holds the normal mode key bindings
+type Keymap struct {
+	leader   string
+	bindings []binding
+}
+
+// DefaultKeymap returns the built-in key bindings
+func DefaultKeymap() Keymap {
+	keymap, err := LoadKeymap("", nil)
+	if err != nil {
+		panic(err) // The default bindings are valid
+	}
+	return keymap
+}
+
+// LoadKeymap builds a keymap from the defaults, a leader key and extra
+// bindings from the config file, which map sequences like "<leader>t" or
+// "dd" to action names. Binding a sequence to "none" removes it.
+func LoadKeymap(leader string, overrides map[string]string) (Keymap, error) {
+	if leader == "" {
+		leader = DefaultLeader
+	}
+	leaderKeys, err := parseSequence(leader, "")
+	if err != nil || len(leaderKeys) != 1 {
+		return Keymap{}, fmt.Errorf("invalid leader key %q, use a single key like , or <space>", leader)
+	}
+	keymap := Keymap{leader: leaderKeys[0]}
+
+	for _, b := range defaultBindings {
+		keys, err := parseSequence(b.seq, keymap.leader)
+		if err != nil {
+			return Keymap{}, err
+		}
+		keymap.bind(keys, b.action)
+	}
+
+	// Apply the overrides in a stable order, error messages depend on it
+	seqs := make([]string, 0, len(overrides))
+	for seq := range overrides {
+		seqs = append(seqs, seq)
+	}
+	slices.Sort(seqs)
+	for _, seq := range seqs {
+		action := Action(overrides[seq])
+		if action != ActionNone && !isAction(action) {
+			return Keymap{}, fmt.Errorf("unknown action %q for %s, use one of: %s", action, seq, strings.Join(ActionNames(), ", "))
+		}
+		keys, err := parseSequence(seq, keymap.leader)
+		if err != nil {
+			return Keymap{}, err
+		}
+		keymap.bind(keys, action)
+	}
+
+	// A sequence that starts another one would make the longer one unreachable
+	for _, a := range keymap.bindings {
+		for _, b := range keymap.bindings {
+			if len(a.keys) < len(b.keys) && slices.Equal(a.keys, b.keys[:len(a.keys)]) {
+				return Keymap{}, fmt.Errorf("%s hides %s, bind one of them to none", formatKeys(a.keys), formatKeys(b.keys))
+			}
+		}
+	}
+
+	return keymap, nil
+}
+
+// bind maps keys to action, replacing any binding of the same keys
+// ActionNone only removes the binding.
+func (k *Keymap) bind(keys []string, action Action) {
+	k.bindings = slices.DeleteFunc(k.bindings, func(b binding) bool {
+		return slices.Equal(b.keys, keys)
+	})
+	if action != ActionNone {
+		k.bindings = append(k.bindings, binding{keys: keys, action: action})
+	}
+}
+
+// Lookup returns the action bound to keys, and whether keys start a longer
+// sequence that needs more keys
+func (k Keymap) Lookup(keys []string) (action Action, pending bool) {
+	for _, b := range k.bindings {
+		if slices.Equal(b.keys, keys) {
+			return b.action, false
+		}
+		if len(b.keys) > len(keys) && slices.Equal(b.keys[:len(keys)], keys) {
+			pending = true
+		}
+	}
+	return "", pending
+}
+
+// IsLeader reports whether key is the leader key
+func (k Keymap) IsLeader(key string) bool {
+	return key == k.leader
+}
+
+// Help describes the bindings that start with prefix, one line per group of
+// actions. Sequences are shown without the prefix.
+func (k Keymap) Help(prefix []string) string {
+	var lines []string
+	var group string
+	var items []string
+	flush := func() {
+		if len(items) > 0 {
+			lines = append(lines, group+": "+strings.Join(items, " • "))
+		}
+		items = nil
+	}
+
+	for _, info := range actions {
+		var seqs []string
+		for _, b := range k.bindings {
+			if b.action == info.action && len(b.keys) > len(prefix) && slices.Equal(b.keys[:len(prefix)], prefix) {
+				seqs = append(seqs, k.formatHelpKeys(b.keys[len(prefix):], len(prefix) == 0))
+			}
+		}
+		if len(seqs) == 0 {
+			continue
+		}
+		if info.group != group {
+			flush()
+			group = info.group
+		}
+		items = append(items, strings.Join(seqs, "/")+"="+info.description)
+	}
+	flush()
+
+	return strings.Join(lines, "\n")
+}
+
+// formatHelpKeys formats keys for the help text, with the leader key shown as
+// <leader> when leader is true
+func (k Keymap) formatHelpKeys(keys []string, leader bool) string {
+	if leader && len(keys) > 1 && keys[0] == k.leader {
+		return "<leader>" + formatKeys(keys[1:])
+	}
+	return formatKeys(keys)
+}
+
+// LeaderName returns the leader key as shown in the help text
+func (k Keymap) LeaderName() string {
+	return formatKeys([]string{k.leader})
+}
+
+// isAction reports whether action is a known action
+func isAction(action Action) bool {
+	return slices.ContainsFunc(actions, func(info actionInfo) bool {
+		return info.action == action
+	})
+}
+
+// ActionNames returns the names of all actions
+func ActionNames() []string {
+	names := make([]string, 0, len(actions)+1)
+	for _, info := range actions {
+		names = append(names, string(info.action))
+	}
+	return append(names, string(ActionNone))
+}
+
+// specialKeys are the key names allowed between angle brackets
+var specialKeys = map[string]string{
+	"space":     " ",
+	"lt":        "<",
+	"esc":       "esc",
+	"enter":     "enter",
+	"cr":        "enter",
+	"tab":       "tab",
+	"backspace": "backspace",
+	"bs":        "backspace",
+	"del":       "delete",
+	"up":        "up",
+	"down":      "down",
+	"left":      "left",
+	"right":     "right",
+	"home":      "home",
+	"end":       "end",
+	"delete":    "delete",
+	"pageup":    "pgup",
+	"pagedown":  "pgdown",
+	"pgup":      "pgup",
+	"pgdown":    "pgdown",
+}
+
+// parseSequence parses a key sequence in vim-like notation into keys as
+// reported by tea.KeyMsg.String(). Plain characters are keys of their own,
+// <C-x> is ctrl+x, <leader> the leader key and <space>, <esc>, <enter>,
+// <up> and so on name special keys.
+func parseSequence(seq, leader string) ([]string, error) {
+	var keys []string
+	for rest := seq; rest != ""; {
+		if rest[0] == '<' {
+			if end := strings.IndexByte(rest, '>'); end > 1 {
+				key, err := parseSpecialKey(rest[1:end], leader)
+				if err != nil {
+					return nil, fmt.Errorf("invalid key sequence %q: %w", seq, err)
+				}
+				keys = append(keys, key)
+				rest = rest[end+1:]
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(rest)
+		keys = append(keys, string(r))
+		rest = rest[size:]
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("empty key sequence")
+	}
+	return keys, nil
+}
+
+// parseSpecialKey parses the name of a key between angle brackets
+func parseSpecialKey(name, leader string) (string, error) {
+	lower := strings.ToLower(name)
+	if lower == "leader" {
+		if leader == "" {
+			return "", fmt.Errorf("the leader key can't be <leader>")
+		}
+		return leader, nil
+	}
+	if key, ok := specialKeys[lower]; ok {
+		return key, nil
+	}
+	if letter, ok := strings.CutPrefix(lower, "c-"); ok && len(letter) == 1 {
+		return "ctrl+" + letter, nil
+	}
+	return "", fmt.Errorf("unknown key <%s>", name)
+}
+
+// formatKeys formats keys for display, in the notation parseSequence reads
+func formatKeys(keys []string) string {
+	var b strings.Builder
+	for _, key := range keys {
+		switch {
+		case key == " ":
+			b.WriteString("<space>")
+		case key == "<":
+			b.WriteString("<lt>")
+		case strings.HasPrefix(key, "ctrl+"):
+			b.WriteString("<C-" + strings.TrimPrefix(key, "ctrl+") + ">")
+		case utf8.RuneCountInString(key) > 1:
+			b.WriteString("<" + key + ">")
+		default:
+			b.WriteString(key)
+		}
+	}
+	return b.String()
+}
@@ -1,8 +1,10 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
+	"slices"
 	"strings"
 	"tada/internal/todo"
 	"time"
@@ -20,6 +22,8 @@ const (
 	ModeCommand
 	ModeInsert
 	ModeVisual
+	ModeSearch
+	ModeArchive
 )
 
 func (m Mode) String() string {
@@ -32,144 +36,77 @@ func (m Mode) String() string {
 		return "INSERT"
 	case ModeVisual:
 		return "VISUAL"
+	case ModeSearch:
+		return "SEARCH"
+	case ModeArchive:
+		return "ARCHIVE"
 	default:
 		return "UNKNOWN"
 	}
 }
 
-// TodoWithIndex wraps a todo item with its index in the main todos slice
-type TodoWithIndex struct {
-	Item  todo.Item
-	Index int
-}
-
-// ContextList represents a group of todos for a specific context
-type ContextList struct {
-	Context string
-	Todos   []TodoWithIndex
-}
-
-// priorityValue returns a sort value for priority (lower is higher priority)
-func priorityValue(priority string) int {
-	if priority == "" {
-		return 1000 // Unprioritized items come last
-	}
-	// (A) = 0, (B) = 1, ..., (Z) = 25
-	return int(priority[0] - 'A')
-}
-
-// sortTodosByPriority sorts todos by completion status first (uncompleted before completed),
-// then by priority within each group (A is highest, unprioritized is lowest)
-func sortTodosByPriority(todos []TodoWithIndex) {
-	// Simple bubble sort by completion status, then priority
-	for i := 0; i < len(todos); i++ {
-		for j := i + 1; j < len(todos); j++ {
-			// First compare completion status
-			iCompleted := todos[i].Item.Completed
-			jCompleted := todos[j].Item.Completed
-
-			// If completion status differs, uncompleted tasks come first
-			if iCompleted != jCompleted {
-				if iCompleted && !jCompleted {
-					todos[i], todos[j] = todos[j], todos[i]
-				}
-				continue
-			}
-
-			// If completion status is the same, sort by priority
-			iPriority := priorityValue(todos[i].Item.Priority)
-			jPriority := priorityValue(todos[j].Item.Priority)
-			if iPriority > jPriority {
-				todos[i], todos[j] = todos[j], todos[i]
-			}
-		}
-	}
-}
-
-// groupTodosByContext groups todos by their contexts
-func groupTodosByContext(todos []todo.Item) []ContextList {
-	contextMap := make(map[string][]TodoWithIndex)
-
-	// Group todos by context
-	for i, item := range todos {
-		// Skip completed todos older than 5 days
-		if !item.ShouldBeVisible() {
-			continue
-		}
-
-		todoWithIdx := TodoWithIndex{Item: item, Index: i}
-		if len(item.Contexts) == 0 {
-			// No context, put in "No Context" list
-			contextMap["No Context"] = append(contextMap["No Context"], todoWithIdx)
-		} else {
-			// Add to each context it belongs to
-			for _, context := range item.Contexts {
-				contextMap[context] = append(contextMap[context], todoWithIdx)
-			}
-		}
-	}
-
-	// Sort todos within each context by priority
-	for _, todos := range contextMap {
-		sortTodosByPriority(todos)
-	}
-
-	// Convert map to sorted list
-	var lists []ContextList
-
-	// Add "No Context" first if it exists
-	if items, ok := contextMap["No Context"]; ok {
-		lists = append(lists, ContextList{Context: "No Context", Todos: items})
-		delete(contextMap, "No Context")
-	}
-
-	// Add other contexts in alphabetical order
-	contexts := make([]string, 0, len(contextMap))
-	for context := range contextMap {
-		contexts = append(contexts, context)
-	}
-
-	// Simple sort for contexts (alphabetical)
-	for i := 0; i < len(contexts); i++ {
-		for j := i + 1; j < len(contexts); j++ {
-			if contexts[i] > contexts[j] {
-				contexts[i], contexts[j] = contexts[j], contexts[i]
-			}
-		}
-	}
-
-	for _, context := range contexts {
-		lists = append(lists, ContextList{Context: context, Todos: contextMap[context]})
-	}
-
-	return lists
-}
-
 // Model represents the application state
 type Model struct {
 	todos              []todo.Item
-	contextLists       []ContextList // Grouped todos by context
-	listCursor         int           // Which context list is selected
-	itemCursor         int           // Which item in the current list is selected
+	groups             []todo.Group // Grouped todos, by context unless grouping says otherwise
+	listCursor         int          // Which group is selected
+	itemCursor         int          // Which item in the current list is selected
 	mode               Mode
 	filename           string
 	width              int
 	height             int
-	commandInput       textinput.Model // Text input for command mode
-	insertInput        textinput.Model // Text input for insert mode
-	editingIndex       int             // Index of the todo being edited in insert mode (-1 if adding new)
-	styles             Styles          // Theme and styling
-	leaderKey          string          // Leader key (default: space)
-	waitingLeader      bool            // True when waiting for leader command
-	confirmingDelete   bool            // True when waiting for delete confirmation
-	deleteConfirmIndex int             // Index of todo to delete after confirmation
-	availableCommands  []string        // List of available commands for autocomplete
-	showAutocomplete   bool            // True when showing autocomplete suggestions
-	autocompleteCursor int             // Index of selected autocomplete suggestion
+	commandInput       textinput.Model     // Text input for command mode
+	insertInput        textinput.Model     // Text input for insert mode
+	editingIndex       int                 // Index of the todo being edited in insert mode (-1 if adding new)
+	styles             Styles              // Theme and styling
+	keymap             Keymap              // Normal mode key bindings
+	pendingKeys        []string            // Keys typed so far of a longer sequence, like the leader key
+	confirmingDelete   bool                // True when waiting for delete confirmation
+	deleteConfirmIndex int                 // Index of todo to delete after confirmation
+	availableCommands  []string            // List of available commands for autocomplete
+	showAutocomplete   bool                // True when showing autocomplete suggestions
+	autocompleteCursor int                 // Index of selected autocomplete suggestion
+	showFuture         bool                // True when tasks with a future threshold date are shown
+	fileVersion        todo.FileVersion    // Version of todo.txt the todos were loaded from or saved as
+	dirty              bool                // True when the todos have changes that are not on disk
+	conflict           bool                // True when todo.txt changed on disk while dirty
+	history            history             // Changes that can be undone and redone
+	pendingJournal     []todo.JournalEntry // Journal entries written with the next save
+	visual             bool                // True while a range of items is selected
+	visualAnchor       int                 // Item in the current list where the selection started
+	waitingPriority    bool                // True when waiting for the priority to set on the selection
+	searchInput        textinput.Model     // Text input for search mode
+	searchPattern      string              // Last search pattern, used by n/N and highlighted
+	searchOrigin       position            // Cursor position when the search prompt was opened
+	filter             todo.Query          // Only todos matching the filter are shown
+	grouping           todo.Grouping       // How todos are grouped into lists
+	defaultGrouping    todo.Grouping       // Grouping restored by :group without argument
+	offset             int                 // First line of the lists shown in the viewport
+	offsetLayout       layout              // Layout the offset was computed for
+	groupsVersion      int                 // Incremented whenever the groups are rebuilt
+	status             status              // Feedback or error message below the mode line
+	dateOnAdd          bool                // True when new tasks get today's date as creation date
+	archive            todo.Archive        // When and where :archive moves completed todos
+	browsing           bool                // True while the archive browser replaces the lists
+	archived           []todo.ArchivedItem // Todos of all archive files, while browsing
+	archiveQuery       todo.Query          // Only archived todos matching the query are shown
+	archiveMarks       map[int]bool        // Indexes in archived of the todos marked for restore
+	browseOrigin       position            // Cursor position when the archive browser was opened
+}
+
+// Options configures a new TUI model, the zero value gives the defaults
+type Options struct {
+	GroupBy todo.Grouping // How todos are grouped, by context if empty
+	Theme   *Theme        // Colors, the default theme if nil
+	Keymap  *Keymap       // Key bindings, the default ones if nil
+
+	DateOnAdd bool          // Stamp new tasks with today's date as creation date
+	Archive   *todo.Archive // Archive age and layout, the defaults next to filename if nil
 }
 
 // NewModel creates a new TUI model
-func NewModel(filename string) Model {
+func NewModel(filename string, opts Options) Model {
+	version, _ := todo.StatFile(filename)
 	todos, err := todo.LoadFromFile(filename)
 	if err != nil {
 		// If file doesn't exist, start with empty list
@@ -178,8 +115,16 @@ func NewModel(filename string) Model {
 
 	// Initialize theme and styles
 	theme := DefaultTheme()
+	if opts.Theme != nil {
+		theme = *opts.Theme
+	}
 	styles := NewStyles(theme)
 
+	keymap := DefaultKeymap()
+	if opts.Keymap != nil {
+		keymap = *opts.Keymap
+	}
+
 	// Initialize command input
 	cmdInput := textinput.New()
 	cmdInput.Placeholder = "enter command..."
@@ -196,34 +141,68 @@ func NewModel(filename string) Model {
 	insInput.TextStyle = styles.InputText
 	insInput.CharLimit = 500
 
+	// Initialize search input
+	searchInput := textinput.New()
+	searchInput.Placeholder = `search... (\v regex, \c ignore case)`
+	searchInput.Prompt = "/"
+	searchInput.PromptStyle = styles.CommandPrompt
+	searchInput.TextStyle = styles.InputText
+	searchInput.CharLimit = 200
+
+	grouping := opts.GroupBy
+	if grouping == "" {
+		grouping = todo.GroupContext
+	}
+
+	archive := todo.DefaultArchive(filepath.Dir(filename))
+	if opts.Archive != nil {
+		archive = *opts.Archive
+	}
+
 	return Model{
 		todos:              todos,
-		contextLists:       groupTodosByContext(todos),
+		groups:             todo.GroupTodos(todos, grouping, todo.ViewOptions{ArchiveAge: &archive.Age}),
 		listCursor:         0,
 		itemCursor:         0,
 		mode:               ModeNormal,
 		filename:           filename,
 		commandInput:       cmdInput,
 		insertInput:        insInput,
+		searchInput:        searchInput,
 		editingIndex:       -1,
 		styles:             styles,
-		leaderKey:          " ", // Space is the default leader key
-		waitingLeader:      false,
+		keymap:             keymap,
 		confirmingDelete:   false,
 		deleteConfirmIndex: -1,
-		availableCommands:  []string{"add", "edit", "done", "delete", "del", "archive", "sort"},
+		availableCommands:  []string{"add", "edit", "done", "delete", "del", "archive", "sort", "future", "filter", "group", "nohlsearch", "pri", "tag", "untag", "undo", "redo", "reload", "write", "quit"},
 		showAutocomplete:   false,
 		autocompleteCursor: 0,
+		fileVersion:        version,
+		grouping:           grouping,
+		defaultGrouping:    grouping,
+		dateOnAdd:          opts.DateOnAdd,
+		archive:            archive,
 	}
 }
 
 // Init initializes the model
 func (m Model) Init() tea.Cmd {
-	return nil
+	return watchFile()
 }
 
 // Update handles messages and updates the model
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+	if m, ok := model.(Model); ok {
+		// Keep the cursor on screen whatever moved it
+		m.followCursor()
+		return m, cmd
+	}
+	return model, cmd
+}
+
+// update handles a message, Update keeps the cursor in view afterwards
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 
@@ -235,6 +214,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tea.KeyMsg:
 		return m.handleKeyPress(msg)
+
+	case fileCheckMsg:
+		cmd := m.checkFile()
+		return m, tea.Batch(cmd, watchFile())
+
+	case clearStatusMsg:
+		m.clearStatus(msg.id)
+		return m, nil
 	}
 
 	// Update textinput components for cursor blink and other messages
@@ -244,6 +231,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	} else if m.mode == ModeInsert {
 		m.insertInput, cmd = m.insertInput.Update(msg)
 		cmds = append(cmds, cmd)
+	} else if m.mode == ModeSearch {
+		m.searchInput, cmd = m.searchInput.Update(msg)
+		cmds = append(cmds, cmd)
 	}
 
 	return m, tea.Batch(cmds...)
@@ -253,7 +243,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Global quit keys
 	if msg.String() == "ctrl+c" {
-		return m, tea.Quit
+		return m.quit()
 	}
 
 	switch m.mode {
@@ -265,6 +255,10 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleInsertMode(msg)
 	case ModeVisual:
 		return m.handleVisualMode(msg)
+	case ModeSearch:
+		return m.handleSearchMode(msg)
+	case ModeArchive:
+		return m.handleArchiveMode(msg)
 	}
 
 	return m, nil
@@ -288,44 +282,31 @@ func (m Model) handleNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	// Check if we're waiting for a leader command
-	if m.waitingLeader {
-		m.waitingLeader = false // Reset leader mode
-		switch msg.String() {
-		case "e":
-			// Edit current task
-			return m.leaderEdit()
-		case "a", "n":
-			// Add new task
-			return m.leaderAdd()
-		case "c", "d":
-			// Complete current task
-			return m.leaderDone()
-		case "r", "x":
-			// Delete current task
-			return m.leaderDelete()
-		case "s":
-			// Sort tasks
-			return m.leaderSort()
-		case "esc":
-			// Cancel leader mode
-			return m, nil
-		}
-		// Unknown leader command, just return
+	// Collect keys until they form a bound sequence
+	keys := append(slices.Clone(m.pendingKeys), msg.String())
+	action, pending := m.keymap.Lookup(keys)
+	if pending {
+		m.pendingKeys = keys
 		return m, nil
 	}
-
-	switch msg.String() {
-	case " ":
-		// Leader key pressed
-		m.waitingLeader = true
+	m.pendingKeys = nil
+	if action == "" {
+		// Unbound sequences are dropped, which is how esc cancels the leader
 		return m, nil
-	case ":":
+	}
+
+	return m.runAction(action)
+}
+
+// runAction performs a normal mode action
+func (m Model) runAction(action Action) (tea.Model, tea.Cmd) {
+	switch action {
+	case ActionCommand:
 		m.mode = ModeCommand
 		m.commandInput.Reset()
 		m.commandInput.Focus()
 		return m, textinput.Blink
-	case "i", "enter":
+	case ActionInsert:
 		m.mode = ModeInsert
 
 		// Get current todo to edit
@@ -333,7 +314,7 @@ func (m Model) handleNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if idx != -1 {
 			// Prefill with current todo description
 			m.editingIndex = idx
-			m.insertInput.SetValue(m.todos[idx].Raw)
+			m.insertInput.SetValue(m.todos[idx].String())
 		} else {
 			// No todo selected, will add new one
 			m.editingIndex = -1
@@ -342,48 +323,84 @@ func (m Model) handleNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 		m.insertInput.Focus()
 		return m, textinput.Blink
-	case "v":
-		m.mode = ModeVisual
-	case "q":
-		return m, tea.Quit
-	case "up", "k":
+	case ActionVisual:
+		return m.enterVisual()
+	case ActionSearch:
+		return m.startSearch()
+	case ActionSearchNext:
+		return m.searchNext(true)
+	case ActionSearchPrev:
+		return m.searchNext(false)
+	case ActionUndo:
+		cmd := m.undoWithStatus()
+		return m, cmd
+	case ActionRedo:
+		cmd := m.redoWithStatus()
+		return m, cmd
+	case ActionDismiss:
+		m.dismissStatus()
+	case ActionToggleFuture:
+		// Toggle tasks with a future threshold date
+		m.showFuture = !m.showFuture
+		m.refreshGroups()
+	case ActionQuit:
+		return m.quit()
+	case ActionEdit:
+		return m.leaderEdit()
+	case ActionAdd:
+		return m.leaderAdd()
+	case ActionComplete:
+		return m.leaderDone()
+	case ActionDelete:
+		return m.leaderDelete()
+	case ActionSort:
+		return m.leaderSort()
+	case ActionTop:
+		m.moveCursorTo(0)
+	case ActionBottom:
+		m.moveCursorTo(len(m.positions()) - 1)
+	case ActionHalfPageDown:
+		m.moveCursor(m.halfPage())
+	case ActionHalfPageUp:
+		m.moveCursor(-m.halfPage())
+	case ActionUp:
 		// Move up within current list
 		if m.itemCursor > 0 {
 			m.itemCursor--
 		} else if m.listCursor > 0 {
 			// Move to previous list
 			m.listCursor--
-			if len(m.contextLists) > 0 && m.listCursor < len(m.contextLists) {
-				m.itemCursor = len(m.contextLists[m.listCursor].Todos) - 1
+			if len(m.groups) > 0 && m.listCursor < len(m.groups) {
+				m.itemCursor = len(m.groups[m.listCursor].Todos) - 1
 			}
 		}
-	case "down", "j":
+	case ActionDown:
 		// Move down within current list
-		if len(m.contextLists) > 0 && m.listCursor < len(m.contextLists) {
-			if m.itemCursor < len(m.contextLists[m.listCursor].Todos)-1 {
+		if len(m.groups) > 0 && m.listCursor < len(m.groups) {
+			if m.itemCursor < len(m.groups[m.listCursor].Todos)-1 {
 				m.itemCursor++
-			} else if m.listCursor < len(m.contextLists)-1 {
+			} else if m.listCursor < len(m.groups)-1 {
 				// Move to next list
 				m.listCursor++
 				m.itemCursor = 0
 			}
 		}
-	case "left", "h":
+	case ActionPrevList:
 		// Move to previous list
 		if m.listCursor > 0 {
 			m.listCursor--
 			// Adjust item cursor if needed
-			if len(m.contextLists) > 0 && m.itemCursor >= len(m.contextLists[m.listCursor].Todos) {
-				m.itemCursor = len(m.contextLists[m.listCursor].Todos) - 1
+			if len(m.groups) > 0 && m.itemCursor >= len(m.groups[m.listCursor].Todos) {
+				m.itemCursor = len(m.groups[m.listCursor].Todos) - 1
 			}
 		}
-	case "right", "l":
+	case ActionNextList:
 		// Move to next list
-		if len(m.contextLists) > 0 && m.listCursor < len(m.contextLists)-1 {
+		if len(m.groups) > 0 && m.listCursor < len(m.groups)-1 {
 			m.listCursor++
 			// Adjust item cursor if needed
-			if m.itemCursor >= len(m.contextLists[m.listCursor].Todos) {
-				m.itemCursor = len(m.contextLists[m.listCursor].Todos) - 1
+			if m.itemCursor >= len(m.groups[m.listCursor].Todos) {
+				m.itemCursor = len(m.groups[m.listCursor].Todos) - 1
 			}
 		}
 	}
@@ -400,7 +417,7 @@ func (m Model) leaderEdit() (tea.Model, tea.Cmd) {
 	if idx != -1 {
 		// Prefill with current todo description
 		m.editingIndex = idx
-		m.insertInput.SetValue(m.todos[idx].Raw)
+		m.insertInput.SetValue(m.todos[idx].String())
 	} else {
 		// No todo selected, will add new one
 		m.editingIndex = -1
@@ -422,34 +439,28 @@ func (m Model) leaderAdd() (tea.Model, tea.Cmd) {
 
 // leaderDone marks the current task as complete
 func (m Model) leaderDone() (tea.Model, tea.Cmd) {
-	// Get current todo
+	// Get current todo, completing a task twice is a no-op
 	_, idx := m.getCurrentTodo()
-	if idx == -1 {
+	if idx == -1 || m.todos[idx].Completed {
 		return m, nil
 	}
 
-	// Mark as completed
-	m.todos[idx].Completed = true
-
-	// Set completion date
-	completionDate := time.Now().Format("2006-01-02")
-	m.todos[idx].CompletionDate = completionDate
-
-	// Update raw string to include 'x' marker and completion date
-	raw := m.todos[idx].Raw
-	if !strings.HasPrefix(raw, "x ") {
-		m.todos[idx].Raw = "x " + completionDate + " " + raw
-	}
+	// Mark as completed, recurring tasks get a fresh copy
+	before := m.snapshot()
+	m.todos = todo.Complete(m.todos, idx, time.Now())
+	m.record(todo.OpComplete, before, nil)
 
 	// Save to file
-	if err := todo.SaveToFile(m.filename, m.todos); err != nil {
-		return m, nil
+	if err := m.save(); err != nil {
+		cmd := m.saveFailed(err)
+		return m, cmd
 	}
 
-	// Refresh context lists (which triggers sorting)
-	m.refreshContextLists()
+	// Refresh groups (which triggers sorting)
+	m.refreshGroups()
 
-	return m, nil
+	cmd := m.setStatus("completed %s", tasks(1))
+	return m, cmd
 }
 
 // leaderDelete enters delete confirmation mode
@@ -469,8 +480,8 @@ func (m Model) leaderDelete() (tea.Model, tea.Cmd) {
 
 // leaderSort sorts tasks by completion status and priority
 func (m Model) leaderSort() (tea.Model, tea.Cmd) {
-	// Refresh context lists (which triggers sorting)
-	m.refreshContextLists()
+	// Refresh groups (which triggers sorting)
+	m.refreshGroups()
 	return m, nil
 }
 
@@ -483,23 +494,27 @@ func (m Model) confirmDelete() (tea.Model, tea.Cmd) {
 	}
 
 	// Remove the item
-	m.todos = append(m.todos[:m.deleteConfirmIndex], m.todos[m.deleteConfirmIndex+1:]...)
+	before := m.snapshot()
+	m.todos = todo.Remove(m.todos, m.deleteConfirmIndex)
+	m.record(todo.OpDelete, before, nil)
 
 	// Save to file
-	if err := todo.SaveToFile(m.filename, m.todos); err != nil {
+	if err := m.save(); err != nil {
 		m.confirmingDelete = false
 		m.deleteConfirmIndex = -1
-		return m, nil
+		cmd := m.saveFailed(err)
+		return m, cmd
 	}
 
-	// Refresh context lists
-	m.refreshContextLists()
+	// Refresh groups
+	m.refreshGroups()
 
 	// Reset confirmation state
 	m.confirmingDelete = false
 	m.deleteConfirmIndex = -1
 
-	return m, nil
+	cmd := m.setStatus("deleted %s", tasks(1))
+	return m, cmd
 }
 
 // cancelDelete cancels the delete confirmation
@@ -510,11 +525,11 @@ func (m *Model) cancelDelete() {
 
 // getCurrentTodo returns the currently selected todo item and its index in the todos slice
 func (m Model) getCurrentTodo() (*todo.Item, int) {
-	if len(m.contextLists) == 0 || m.listCursor >= len(m.contextLists) {
+	if len(m.groups) == 0 || m.listCursor >= len(m.groups) {
 		return nil, -1
 	}
 
-	currentList := m.contextLists[m.listCursor]
+	currentList := m.groups[m.listCursor]
 	if m.itemCursor >= len(currentList.Todos) {
 		return nil, -1
 	}
@@ -530,21 +545,35 @@ func (m Model) getCurrentTodo() (*todo.Item, int) {
 	return &m.todos[idx], idx
 }
 
-// refreshContextLists rebuilds the context lists after todos change
-func (m *Model) refreshContextLists() {
-	m.contextLists = groupTodosByContext(m.todos)
+// viewOptions returns the options that decide which todos are shown
+func (m Model) viewOptions() todo.ViewOptions {
+	opts := todo.ViewOptions{ShowFuture: m.showFuture, ArchiveAge: &m.archive.Age}
+	if !m.filter.IsEmpty() {
+		opts.Filter = m.filter.Match
+	}
+	return opts
+}
+
+// refreshGroups rebuilds the groups after todos change
+func (m *Model) refreshGroups() {
+	m.groupsVersion++
+	if m.browsing {
+		m.groups = m.archiveGroups()
+	} else {
+		m.groups = todo.GroupTodos(m.todos, m.grouping, m.viewOptions())
+	}
 
 	// Ensure cursors are still valid
-	if m.listCursor >= len(m.contextLists) {
-		m.listCursor = len(m.contextLists) - 1
+	if m.listCursor >= len(m.groups) {
+		m.listCursor = len(m.groups) - 1
 	}
 	if m.listCursor < 0 {
 		m.listCursor = 0
 	}
 
-	if len(m.contextLists) > 0 && m.listCursor < len(m.contextLists) {
-		if m.itemCursor >= len(m.contextLists[m.listCursor].Todos) {
-			m.itemCursor = len(m.contextLists[m.listCursor].Todos) - 1
+	if len(m.groups) > 0 && m.listCursor < len(m.groups) {
+		if m.itemCursor >= len(m.groups[m.listCursor].Todos) {
+			m.itemCursor = len(m.groups[m.listCursor].Todos) - 1
 		}
 		if m.itemCursor < 0 {
 			m.itemCursor = 0
@@ -560,10 +589,10 @@ func (m Model) executeCommand() (Model, tea.Cmd) {
 		return m, nil
 	}
 
-	cmd := parts[0]
+	name := parts[0]
 	args := strings.Join(parts[1:], " ")
 
-	switch cmd {
+	switch name {
 	case "add":
 		return m.cmdAdd(args)
 	case "edit":
@@ -576,9 +605,37 @@ func (m Model) executeCommand() (Model, tea.Cmd) {
 		return m.cmdArchive(args)
 	case "sort":
 		return m.cmdSort(args)
+	case "future":
+		return m.cmdFuture(args)
+	case "filter":
+		return m.cmdFilter(args)
+	case "group":
+		return m.cmdGroup(args)
+	case "nohlsearch", "noh":
+		return m.cmdNoHighlight(args)
+	case "pri":
+		return m.cmdPri(args)
+	case "tag":
+		return m.cmdTag(args)
+	case "untag":
+		return m.cmdUntag(args)
+	case "undo":
+		return m.cmdUndo(args)
+	case "redo":
+		return m.cmdRedo(args)
+	case "reload":
+		return m.cmdReload(args)
+	case "write", "w":
+		return m.cmdWrite(args)
+	case "quit", "q":
+		return m.quit()
+	case "quit!", "q!":
+		return m, tea.Quit
 	}
 
-	return m, nil
+	// Stay in command mode so the command can be fixed
+	cmd := m.setError(fmt.Errorf("unknown command: %s", name))
+	return m, cmd
 }
 
 // cmdAdd adds a new task
@@ -588,24 +645,38 @@ func (m Model) cmdAdd(description string) (Model, tea.Cmd) {
 	}
 
 	// Parse the new todo to extract contexts
-	newItem := todo.Parse(description)
+	newItem := m.newItem(description)
 
+	before := m.snapshot()
 	m.todos = append(m.todos, newItem)
+	m.record(todo.OpAdd, before, nil)
+
+	// Return to normal mode
+	m.mode = ModeNormal
+	m.commandInput.Blur()
 
 	// Save to file
-	if err := todo.SaveToFile(m.filename, m.todos); err != nil {
-		// TODO: Handle error (could add error message to model)
-		return m, nil
+	if err := m.save(); err != nil {
+		cmd := m.saveFailed(err)
+		return m, cmd
 	}
 
-	// Refresh context lists
-	m.refreshContextLists()
+	// Refresh groups
+	m.refreshGroups()
 
-	// Return to normal mode
-	m.mode = ModeNormal
-	m.commandInput.Blur()
+	cmd := m.setStatus("added %s", newItem.Description)
+	return m, cmd
+}
 
-	return m, nil
+// newItem parses the line of a task being added, with today's date as
+// creation date if enabled
+func (m Model) newItem(line string) todo.Item {
+	item := todo.Parse(line)
+	if m.dateOnAdd && item.CreationDate == "" && !item.Completed {
+		item.CreationDate = time.Now().Format("2006-01-02")
+		item = item.Normalize()
+	}
+	return item
 }
 
 // cmdEdit edits the current task
@@ -624,51 +695,91 @@ func (m Model) cmdEdit(newDescription string) (Model, tea.Cmd) {
 	updatedItem := todo.Parse(newDescription)
 
 	// Update the item in todos
+	before := m.snapshot()
 	m.todos[idx] = updatedItem
-
-	// Save to file
-	if err := todo.SaveToFile(m.filename, m.todos); err != nil {
-		return m, nil
-	}
-
-	// Refresh context lists
-	m.refreshContextLists()
+	m.record(todo.OpEdit, before, nil)
 
 	// Return to normal mode
 	m.mode = ModeNormal
 	m.commandInput.Blur()
 
-	return m, nil
+	// Save to file
+	if err := m.save(); err != nil {
+		cmd := m.saveFailed(err)
+		return m, cmd
+	}
+
+	// Refresh groups
+	m.refreshGroups()
+
+	cmd := m.setStatus("updated %s", updatedItem.Description)
+	return m, cmd
 }
 
-// cmdDone marks the current task as complete
+// cmdDone marks the current or selected tasks as complete
 func (m Model) cmdDone(args string) (Model, tea.Cmd) {
-	// Get current todo
-	_, idx := m.getCurrentTodo()
-	if idx == -1 {
-		return m, nil
+	return m.completeSelection()
+}
+
+// cmdDelete deletes the current or selected tasks
+func (m Model) cmdDelete(args string) (Model, tea.Cmd) {
+	return m.deleteSelection()
+}
+
+// cmdPri sets the priority of the current or selected tasks, "-" or no
+// argument clears it
+func (m Model) cmdPri(args string) (Model, tea.Cmd) {
+	priority := strings.TrimSpace(args)
+	if priority == "-" {
+		priority = ""
 	}
+	return m.prioritizeSelection(priority)
+}
 
-	// Mark as completed
-	m.todos[idx].Completed = true
+// cmdArchive archives completed todos that are older than the archive age
+// ":archive browse [query]" opens the archive browser instead.
+func (m Model) cmdArchive(args string) (Model, tea.Cmd) {
+	if fields := strings.Fields(args); len(fields) > 0 && fields[0] == "browse" {
+		return m.openArchive(strings.Join(fields[1:], " "))
+	}
 
-	// Set completion date
-	completionDate := time.Now().Format("2006-01-02")
-	m.todos[idx].CompletionDate = completionDate
+	// Return to normal mode
+	m.mode = ModeNormal
+	m.commandInput.Blur()
 
-	// Update raw string to include 'x' marker and completion date
-	raw := m.todos[idx].Raw
-	if !strings.HasPrefix(raw, "x ") {
-		m.todos[idx].Raw = "x " + completionDate + " " + raw
+	// Archive old completed todos
+	sp := m.savepoint()
+	remainingTodos, archivedTodos := m.archive.Split(m.todos)
+	if len(archivedTodos) == 0 {
+		cmd := m.setStatus("nothing to archive")
+		return m, cmd
 	}
 
-	// Save to file
-	if err := todo.SaveToFile(m.filename, m.todos); err != nil {
-		return m, nil
+	// Update the todos list
+	m.todos = remainingTodos
+	m.record(todo.OpArchive, sp.todos, archivedTodos)
+
+	// Save updated todo list along with the archive files, if that fails
+	// nothing changes
+	if err := m.saveMoving(sp, archivedTodos, nil); errors.Is(err, todo.ErrJournal) {
+		cmd := m.saveFailed(err)
+		return m, cmd
+	} else if err != nil {
+		cmd := m.setError(fmt.Errorf("archive failed: %w", err))
+		return m, cmd
 	}
 
-	// Refresh context lists
-	m.refreshContextLists()
+	// Refresh groups
+	m.refreshGroups()
+
+	cmd := m.setStatus("archived %s", tasks(len(archivedTodos)))
+	return m, cmd
+}
+
+// cmdSort sorts tasks by completion status and priority
+func (m Model) cmdSort(args string) (Model, tea.Cmd) {
+	// Refresh groups (which triggers sorting)
+	m.refreshGroups()
 
 	// Return to normal mode
 	m.mode = ModeNormal
@@ -677,24 +788,50 @@ func (m Model) cmdDone(args string) (Model, tea.Cmd) {
 	return m, nil
 }
 
-// cmdDelete deletes the current task
-func (m Model) cmdDelete(args string) (Model, tea.Cmd) {
-	// Get current todo
-	_, idx := m.getCurrentTodo()
-	if idx == -1 {
-		return m, nil
-	}
+// cmdFuture toggles showing tasks whose threshold date lies in the future
+func (m Model) cmdFuture(args string) (Model, tea.Cmd) {
+	m.showFuture = !m.showFuture
+	m.refreshGroups()
 
-	// Remove the item
-	m.todos = append(m.todos[:idx], m.todos[idx+1:]...)
+	// Return to normal mode
+	m.mode = ModeNormal
+	m.commandInput.Blur()
 
-	// Save to file
-	if err := todo.SaveToFile(m.filename, m.todos); err != nil {
-		return m, nil
+	return m, nil
+}
+
+// cmdFilter only shows todos matching the query, no query shows all todos again
+func (m Model) cmdFilter(args string) (Model, tea.Cmd) {
+	query, err := todo.ParseQuery(args)
+	if err != nil {
+		// Stay in command mode so the query can be fixed
+		cmd := m.setError(err)
+		return m, cmd
 	}
+	m.filter = query
+	m.refreshGroups()
 
-	// Refresh context lists
-	m.refreshContextLists()
+	// Return to normal mode
+	m.mode = ModeNormal
+	m.commandInput.Blur()
+
+	return m, nil
+}
+
+// cmdGroup changes how todos are grouped, no argument restores the default
+func (m Model) cmdGroup(args string) (Model, tea.Cmd) {
+	grouping := m.defaultGrouping
+	if args != "" {
+		var err error
+		if grouping, err = todo.ParseGrouping(args); err != nil {
+			cmd := m.setError(err)
+			return m, cmd
+		}
+	}
+	m.grouping = grouping
+	m.listCursor = 0
+	m.itemCursor = 0
+	m.refreshGroups()
 
 	// Return to normal mode
 	m.mode = ModeNormal
@@ -703,50 +840,85 @@ func (m Model) cmdDelete(args string) (Model, tea.Cmd) {
 	return m, nil
 }
 
-// cmdArchive archives completed todos older than 5 days
-func (m Model) cmdArchive(args string) (Model, tea.Cmd) {
-	// Get the directory of the todo file for placing archive files
-	dir := filepath.Dir(m.filename)
+// cmdNoHighlight clears the search pattern and its highlighting
+func (m Model) cmdNoHighlight(args string) (Model, tea.Cmd) {
+	m.searchPattern = ""
 
-	// Archive old completed todos
-	remainingTodos, err := todo.ArchiveOldCompletedTodos(m.todos, dir)
-	if err != nil {
-		// If archiving fails, just return without changes
-		m.mode = ModeNormal
-		m.commandInput.Blur()
-		return m, nil
-	}
+	// Return to normal mode
+	m.mode = ModeNormal
+	m.commandInput.Blur()
 
-	// Update the todos list
-	m.todos = remainingTodos
+	return m, nil
+}
 
-	// Save updated todo list
-	if err := todo.SaveToFile(m.filename, m.todos); err != nil {
-		m.mode = ModeNormal
-		m.commandInput.Blur()
-		return m, nil
+// countFutureTodos returns the number of tasks hidden by their threshold date
+func (m Model) countFutureTodos() int {
+	count := 0
+	for _, item := range m.todos {
+		if item.IsBeforeThreshold() {
+			count++
+		}
 	}
+	return count
+}
+
+// cmdUndo reverts the most recent change
+func (m Model) cmdUndo(args string) (Model, tea.Cmd) {
+	// Return to normal mode
+	m.mode = ModeNormal
+	m.commandInput.Blur()
 
-	// Refresh context lists
-	m.refreshContextLists()
+	cmd := m.undoWithStatus()
+	return m, cmd
+}
 
+// cmdRedo applies the most recently undone change again
+func (m Model) cmdRedo(args string) (Model, tea.Cmd) {
 	// Return to normal mode
 	m.mode = ModeNormal
 	m.commandInput.Blur()
 
-	return m, nil
+	cmd := m.redoWithStatus()
+	return m, cmd
 }
 
-// cmdSort sorts tasks by completion status and priority
-func (m Model) cmdSort(args string) (Model, tea.Cmd) {
-	// Refresh context lists (which triggers sorting)
-	m.refreshContextLists()
+// cmdReload discards in-memory changes and loads todo.txt from disk
+func (m Model) cmdReload(args string) (Model, tea.Cmd) {
+	// Return to normal mode
+	m.mode = ModeNormal
+	m.commandInput.Blur()
+
+	// Keep the current list if the file can't be read
+	if err := m.reload(); err != nil {
+		cmd := m.setError(fmt.Errorf("reload failed: %w", err))
+		return m, cmd
+	}
+	cmd := m.setStatus("reloaded %s", filepath.Base(m.filename))
+	return m, cmd
+}
+
+// cmdWrite writes the in-memory todos to disk, overwriting external changes
+func (m Model) cmdWrite(args string) (Model, tea.Cmd) {
+	if err := m.write(); err != nil {
+		cmd := m.saveFailed(err)
+		return m, cmd
+	}
 
 	// Return to normal mode
 	m.mode = ModeNormal
 	m.commandInput.Blur()
 
-	return m, nil
+	cmd := m.setStatus("wrote %s", filepath.Base(m.filename))
+	return m, cmd
+}
+
+// quit exits the program, unless changes would be lost: :q! quits anyway
+func (m Model) quit() (Model, tea.Cmd) {
+	if m.dirty || m.conflict {
+		cmd := m.setError(errUnsaved)
+		return m, cmd
+	}
+	return m, tea.Quit
 }
 
 // getAutocompleteSuggestions returns commands that match the current input
@@ -776,6 +948,7 @@ func (m Model) handleCommandMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.mode = ModeNormal
 		m.commandInput.Blur()
 		m.showAutocomplete = false
+		m.visual = false
 		return m, nil
 	case "enter":
 		if m.showAutocomplete {
@@ -788,9 +961,11 @@ func (m Model) handleCommandMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 		}
-		// Execute the command
+		// Execute the command, a selection only lasts for one command
 		m.showAutocomplete = false
-		return m.executeCommand()
+		m, cmd := m.executeCommand()
+		m.visual = false
+		return m, cmd
 	case "tab", "/":
 		// Show autocomplete
 		suggestions := m.getAutocompleteSuggestions()
@@ -848,22 +1023,30 @@ func (m Model) handleInsertMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.editingIndex = -1
 		return m, nil
 	case "enter":
+		var cmd tea.Cmd
 		description := m.insertInput.Value()
 		if description != "" {
+			before := m.snapshot()
+			op := todo.OpAdd
+			item := todo.Parse(description)
 			if m.editingIndex >= 0 && m.editingIndex < len(m.todos) {
 				// Edit existing todo
-				updatedItem := todo.Parse(description)
-				m.todos[m.editingIndex] = updatedItem
+				op = todo.OpEdit
+				m.todos[m.editingIndex] = item
 			} else {
 				// Add new todo
-				newItem := todo.Parse(description)
-				m.todos = append(m.todos, newItem)
+				item = m.newItem(description)
+				m.todos = append(m.todos, item)
 			}
+			m.record(op, before, nil)
 
 			// Save to file
-			if err := todo.SaveToFile(m.filename, m.todos); err == nil {
-				// Refresh context lists
-				m.refreshContextLists()
+			if err := m.save(); err != nil {
+				cmd = m.saveFailed(err)
+			} else {
+				// Refresh groups
+				m.refreshGroups()
+				cmd = m.setStatus("%s %s", opVerbs[op], item.Description)
 			}
 		}
 
@@ -871,7 +1054,7 @@ func (m Model) handleInsertMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.mode = ModeNormal
 		m.insertInput.Blur()
 		m.editingIndex = -1
-		return m, nil
+		return m, cmd
 	}
 
 	// Let the textinput handle the key
@@ -880,16 +1063,6 @@ func (m Model) handleInsertMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
-// handleVisualMode handles key presses in visual mode
-func (m Model) handleVisualMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "esc":
-		m.mode = ModeNormal
-	}
-
-	return m, nil
-}
-
 // getPriorityStyle returns the appropriate style for a priority
 func (m Model) getPriorityStyle(priority string) lipgloss.Style {
 	if priority == "" {
@@ -910,38 +1083,123 @@ func (m Model) getPriorityStyle(priority string) lipgloss.Style {
 	}
 }
 
+// dueState describes how urgent an item's due date is
+type dueState int
+
+const (
+	dueNone dueState = iota
+	dueLater
+	dueSoon
+	dueToday
+	dueOverdue
+)
+
+// getDueState classifies the due date of an item relative to now
+// Completed items and items without a valid due date have no due state.
+func getDueState(item todo.Item, now time.Time) dueState {
+	if item.Completed {
+		return dueNone
+	}
+	due, ok := item.DueDate()
+	if !ok {
+		return dueNone
+	}
+
+	days := todo.DaysUntil(due, now)
+	switch {
+	case days < 0:
+		return dueOverdue
+	case days == 0:
+		return dueToday
+	case days < 7:
+		return dueSoon
+	default:
+		return dueLater
+	}
+}
+
+// renderDueBadge renders the due date badge for an item, or "" if it has none
+func (m Model) renderDueBadge(item todo.Item, now time.Time) string {
+	state := getDueState(item, now)
+	if state == dueNone {
+		return ""
+	}
+
+	due, _ := item.DueDate()
+	days := todo.DaysUntil(due, now)
+
+	switch state {
+	case dueOverdue:
+		return m.styles.DueOverdue.Render(fmt.Sprintf("overdue %dd", -days))
+	case dueToday:
+		return m.styles.DueToday.Render("due today")
+	case dueSoon:
+		return m.styles.DueSoon.Render("due " + due.Format("Mon"))
+	default:
+		return m.styles.DueLater.Render("due " + due.Format("Jan 02"))
+	}
+}
+
 // View renders the UI
 func (m Model) View() string {
-	var s string
+	footer := m.renderFooter()
+	lines, _ := m.renderLists()
+	return m.renderHeader() + m.renderViewport(lines, m.listHeight(footer)) + footer
+}
+
+// renderHeader renders the title above the lists
+func (m Model) renderHeader() string {
+	return m.styles.AppTitle.Render("✓ TADA") + "\n"
+}
 
-	// Header
-	s += m.styles.AppTitle.Render("✓ TADA") + "\n"
+// renderLists renders the todo lists line by line and returns the index of
+// the line with the cursor, -1 if there is none
+func (m Model) renderLists() ([]listLine, int) {
+	var lines []listLine
+	cursorLine := -1
 
-	// Todo lists by context
-	if len(m.todos) == 0 {
+	if len(m.groups) == 0 && m.browsing {
 		emptyStyle := lipgloss.NewStyle().
 			Foreground(m.styles.Theme.Muted).
 			Italic(true).
 			Padding(2, 4)
-		s += emptyStyle.Render("No todos yet. Press ':add <task>' to create one!") + "\n"
+		lines = appendBlock(lines, emptyStyle.Render(m.archiveEmptyText()))
+	} else if len(m.groups) == 0 && !m.filter.IsEmpty() {
+		emptyStyle := lipgloss.NewStyle().
+			Foreground(m.styles.Theme.Muted).
+			Italic(true).
+			Padding(2, 4)
+		lines = appendBlock(lines, emptyStyle.Render("No todos match the filter. Press ':filter' to clear it."))
+	} else if len(m.todos) == 0 {
+		emptyStyle := lipgloss.NewStyle().
+			Foreground(m.styles.Theme.Muted).
+			Italic(true).
+			Padding(2, 4)
+		lines = appendBlock(lines, emptyStyle.Render("No todos yet. Press ':add <task>' to create one!"))
 	} else {
-		// Render each context list
-		for listIdx, contextList := range m.contextLists {
+		now := time.Now()
+		search := m.activeSearch()
+
+		// Render each group
+		for listIdx, group := range m.groups {
 			// Context header
 			headerStyle := m.styles.ContextHeader
 			if listIdx == m.listCursor {
 				headerStyle = m.styles.ContextHeaderActive
 			}
 
-			contextTitle := fmt.Sprintf("@%s (%d)", contextList.Context, len(contextList.Todos))
-			s += headerStyle.Render(contextTitle) + "\n"
+			groupTitle := fmt.Sprintf("%s (%d)", group.Title, len(group.Todos))
+			lines = appendBlock(lines, headerStyle.Render(groupTitle))
 
 			// Render todos in this context
-			for itemIdx, todoWithIdx := range contextList.Todos {
+			for itemIdx, todoWithIdx := range group.Todos {
 				cursor := "  "
 				cursorStyle := m.styles.TodoCursor
+				selected := m.isSelected(listIdx, itemIdx)
 				if listIdx == m.listCursor && itemIdx == m.itemCursor {
 					cursor = cursorStyle.Render("▸ ")
+				} else if selected {
+					cursor = m.styles.TodoSelected.Render("┃ ")
 				}
 
 				// Priority badge
@@ -958,13 +1216,41 @@ func (m Model) View() string {
 				} else {
 					itemStyle = m.styles.TodoNormal
 				}
+				if selected {
+					itemStyle = itemStyle.Foreground(m.styles.Theme.VisualModeColor).Bold(true)
+				}
+
+				// Due date badge
+				dueBadge := m.renderDueBadge(todoWithIdx.Item, now)
+				if dueBadge != "" {
+					dueBadge = " " + dueBadge
+				}
+
+				description := itemStyle.Render(todoWithIdx.Item.Description)
+				if search != nil {
+					description = m.highlightMatches(todoWithIdx.Item.Description, itemStyle, search)
+				}
 
-				s += fmt.Sprintf("%s%s%s\n", cursor, priorityBadge, itemStyle.Render(todoWithIdx.Item.Description))
+				if listIdx == m.listCursor && itemIdx == m.itemCursor {
+					cursorLine = len(lines)
+				}
+				lines = append(lines, listLine{
+					text:   fmt.Sprintf("%s%s%s%s", cursor, priorityBadge, description, dueBadge),
+					isItem: true,
+				})
 			}
-			s += "\n" // Space between lists
+			lines = append(lines, listLine{}) // Space between lists
 		}
 	}
 
+	return lines, cursorLine
+}
+
+// renderFooter renders everything below the lists: prompts, the mode line,
+// inputs and help
+func (m Model) renderFooter() string {
+	var s string
+
 	// Delete confirmation prompt
 	if m.confirmingDelete && m.deleteConfirmIndex >= 0 && m.deleteConfirmIndex < len(m.todos) {
 		s += "\n"
@@ -984,6 +1270,19 @@ func (m Model) View() string {
 		s += confirmStyle.Render(confirmMsg) + "\n"
 	}
 
+	// Conflict warning when todo.txt changed on disk while we had unsaved changes
+	if m.conflict {
+		s += "\n"
+		conflictStyle := lipgloss.NewStyle().
+			Bold(true).
+			Foreground(m.styles.Theme.Warning).
+			Padding(0, 2).
+			Border(lipgloss.DoubleBorder()).
+			BorderForeground(m.styles.Theme.Warning)
+		s += conflictStyle.Render("todo.txt was changed by another program and your changes are not saved.\n"+
+			":reload to load the file (drops your changes) • :write to overwrite it") + "\n"
+	}
+
 	// Footer with mode indicator
 	s += "\n"
 	var modeStyle lipgloss.Style
@@ -997,14 +1296,17 @@ func (m Model) View() string {
 			Background(m.styles.Theme.Danger).
 			Padding(0, 2)
 		modeText = "CONFIRM DELETE"
-	} else if m.waitingLeader {
-		// Show special indicator when waiting for leader command
+	} else if len(m.pendingKeys) > 0 {
+		// Show special indicator when waiting for the rest of a key sequence
 		modeStyle = lipgloss.NewStyle().
 			Bold(true).
 			Foreground(lipgloss.Color("0")).
 			Background(m.styles.Theme.Accent).
 			Padding(0, 2)
-		modeText = "LEADER"
+		modeText = formatKeys(m.pendingKeys)
+		if m.keymap.IsLeader(m.pendingKeys[0]) {
+			modeText = "LEADER"
+		}
 	} else {
 		switch m.mode {
 		case ModeNormal:
@@ -1015,12 +1317,50 @@ func (m Model) View() string {
 			modeStyle = m.styles.ModeCommand
 		case ModeVisual:
 			modeStyle = m.styles.ModeVisual
+		case ModeSearch:
+			modeStyle = m.styles.ModeCommand
+		case ModeArchive:
+			modeStyle = m.styles.ModeVisual
 		}
 		modeText = m.mode.String()
 	}
 
 	s += modeStyle.Render(" " + modeText + " ")
 
+	// Changes that didn't make it to disk
+	if m.dirty {
+		s += " " + m.styles.Unsaved.Render("UNSAVED")
+	}
+
+	// What the archive browser shows
+	if m.browsing {
+		hintStyle := lipgloss.NewStyle().Foreground(m.styles.Theme.Accent)
+		s += hintStyle.Render(m.archiveHint())
+	}
+
+	// Active filter
+	if !m.filter.IsEmpty() && !m.browsing {
+		filterStyle := lipgloss.NewStyle().Foreground(m.styles.Theme.Accent)
+		s += filterStyle.Render("  filter: " + m.filter.String())
+	}
+
+	// Hint about tasks hidden by their threshold date
+	if !m.showFuture && !m.browsing {
+		if count := m.countFutureTodos(); count > 0 {
+			hintStyle := lipgloss.NewStyle().Foreground(m.styles.Theme.Muted).Italic(true)
+			s += hintStyle.Render(fmt.Sprintf("  %d future task(s) hidden", count))
+		}
+	}
+
+	// Feedback and errors
+	if m.status.text != "" {
+		statusStyle := m.styles.StatusInfo
+		if m.status.isError {
+			statusStyle = m.styles.StatusError
+		}
+		s += "\n" + statusStyle.Render(m.status.text)
+	}
+
 	// Command/Insert input prompt
 	if m.mode == ModeCommand {
 		s += "\n" + m.commandInput.View()
@@ -1056,6 +1396,12 @@ func (m Model) View() string {
 		}
 	} else if m.mode == ModeInsert {
 		s += "\n" + m.insertInput.View()
+	} else if m.mode == ModeSearch {
+		s += "\n" + m.searchInput.View()
+		if status := m.searchStatus(); status != "" {
+			hintStyle := lipgloss.NewStyle().Foreground(m.styles.Theme.Muted).Italic(true)
+			s += hintStyle.Render("  " + status)
+		}
 	}
 
 	// Help text
@@ -1064,21 +1410,25 @@ func (m Model) View() string {
 	// Special help when waiting for delete confirmation
 	if m.confirmingDelete {
 		help = "Confirm: d/x/enter=delete • esc=cancel"
-	} else if m.waitingLeader {
-		// Special help when waiting for leader command
-		help = "Leader: e=edit • a/n=add • c/d=complete • r/x=delete • s=sort • esc=cancel"
+	} else if m.waitingPriority {
+		help = "Priority: A-Z=set priority of selected tasks • -=remove priority • esc=cancel"
+	} else if len(m.pendingKeys) > 0 {
+		// Special help with the keys that complete the sequence
+		help = m.keymap.Help(m.pendingKeys) + " • esc=cancel"
 	} else {
 		switch m.mode {
 		case ModeNormal:
-			help = "Hotkeys: <Space> = Leader\n" +
-				"Modes: i/enter = Insert • : = Command • v = Visual • <Esc> = Back to Normal\n" +
-				"Navigation: j/k=up/down • h/l=prev/next list • q=quit"
+			help = "Leader: " + m.keymap.LeaderName() + "\n" + m.keymap.Help(nil)
 		case ModeInsert:
 			help = "enter: save changes • esc: cancel"
 		case ModeCommand:
-			help = "add <task> • edit <new text> • done • delete/del • archive • sort • tab//: autocomplete • enter: execute • esc: cancel"
+			help = "add <task> • edit <new text> • done • delete/del • pri <A-Z|-> • tag/untag <@context|+project|key:value> • archive [browse [query]] • filter [query] • group [context|project|priority|due|created|none] • sort • future • noh • undo • redo • reload • write • quit/q! • tab//: autocomplete • enter: execute • esc: cancel"
+		case ModeSearch:
+			help = `enter: search • esc: cancel • \v: regular expression • \c/\C: ignore/match case (default: smartcase)`
+		case ModeArchive:
+			help = "j/k: move • m: mark • enter/r: restore marked or current task • /: search • esc/q: close"
 		case ModeVisual:
-			help = "esc: back to normal mode"
+			help = "j/k: extend selection • c: complete • d/x: delete • p<A-Z|->: priority • +/-: add/remove @context, +project or key:value • : command • esc: cancel"
 		}
 	}
 
@@ -0,0 +1,230 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var errNoPattern = errors.New("no previous search pattern")
+
+// position identifies an item by its list and its index within the list
+type position struct {
+	list int
+	item int
+}
+
+// compileSearch turns a search pattern into a regular expression
+// Like vim's smartcase, the search ignores case unless the pattern contains
+// an uppercase letter; \c and \C force ignoring or matching case. The
+// pattern is literal text unless it contains \v, which makes it a regular
+// expression.
+func compileSearch(pattern string) (*regexp.Regexp, error) {
+	ignoreCase := !strings.ContainsFunc(pattern, unicode.IsUpper)
+	if strings.Contains(pattern, `\c`) {
+		ignoreCase = true
+	} else if strings.Contains(pattern, `\C`) {
+		ignoreCase = false
+	}
+	pattern = strings.NewReplacer(`\c`, "", `\C`, "").Replace(pattern)
+
+	isRegex := strings.Contains(pattern, `\v`)
+	pattern = strings.ReplaceAll(pattern, `\v`, "")
+	if pattern == "" {
+		return nil, errNoPattern
+	}
+	if !isRegex {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+
+	return regexp.Compile(pattern)
+}
+
+// startSearch opens the search prompt
+func (m Model) startSearch() (tea.Model, tea.Cmd) {
+	m.mode = ModeSearch
+	m.searchOrigin = position{list: m.listCursor, item: m.itemCursor}
+	m.searchInput.Reset()
+	m.searchInput.Focus()
+	return m, textinput.Blink
+}
+
+// handleSearchMode handles key presses while typing a search pattern
+func (m Model) handleSearchMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		// Cancel the search and go back to where it started
+		m.mode = m.baseMode()
+		m.searchInput.Blur()
+		m.listCursor, m.itemCursor = m.searchOrigin.list, m.searchOrigin.item
+		return m, nil
+	case "enter":
+		// An empty pattern repeats the last search, like in vim
+		if pattern := m.searchInput.Value(); pattern != "" {
+			m.searchPattern = pattern
+		}
+		m.mode = m.baseMode()
+		m.searchInput.Blur()
+		m.listCursor, m.itemCursor = m.searchOrigin.list, m.searchOrigin.item
+		cmd := m.search(true, true)
+		return m, cmd
+	}
+
+	// Let the textinput handle the key
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+
+	// Jump to the first match while typing
+	m.listCursor, m.itemCursor = m.searchOrigin.list, m.searchOrigin.item
+	if re, err := compileSearch(m.searchInput.Value()); err == nil {
+		m.jumpToMatch(re, true, true)
+	}
+	return m, cmd
+}
+
+// searchNext moves the cursor to the next match of the last search, or the
+// previous one when forward is false
+func (m Model) searchNext(forward bool) (tea.Model, tea.Cmd) {
+	cmd := m.search(forward, false)
+	return m, cmd
+}
+
+// search jumps to a match of the last search and reports when there is none
+func (m *Model) search(forward, inclusive bool) tea.Cmd {
+	re, err := compileSearch(m.searchPattern)
+	if err != nil {
+		return m.setError(err)
+	}
+	if !m.jumpToMatch(re, forward, inclusive) {
+		return m.setError(fmt.Errorf("pattern not found: %s", m.searchPattern))
+	}
+	return nil
+}
+
+// activeSearch returns the pattern to highlight: the one being typed in
+// search mode, the last search otherwise
+func (m Model) activeSearch() *regexp.Regexp {
+	pattern := m.searchPattern
+	if m.mode == ModeSearch {
+		pattern = m.searchInput.Value()
+	}
+	re, err := compileSearch(pattern)
+	if err != nil {
+		return nil
+	}
+	return re
+}
+
+// searchMatches returns the positions of all items matching re, in the order
+// they are displayed
+func (m Model) searchMatches(re *regexp.Regexp) []position {
+	var matches []position
+	for listIdx, list := range m.groups {
+		for itemIdx, item := range list.Todos {
+			if re.MatchString(item.Item.Description) {
+				matches = append(matches, position{list: listIdx, item: itemIdx})
+			}
+		}
+	}
+	return matches
+}
+
+// jumpToMatch moves the cursor to the closest match of re in the given
+// direction, wrapping around at the end of the lists. With inclusive, the
+// item under the cursor counts as a match. Returns false if nothing matches.
+func (m *Model) jumpToMatch(re *regexp.Regexp, forward, inclusive bool) bool {
+	matches := m.searchMatches(re)
+	if len(matches) == 0 {
+		return false
+	}
+
+	current := position{list: m.listCursor, item: m.itemCursor}
+	target := matches[0]
+	if !forward {
+		target = matches[len(matches)-1]
+	}
+
+	if forward {
+		for _, match := range matches {
+			if match.after(current) || (inclusive && match == current) {
+				target = match
+				break
+			}
+		}
+	} else {
+		for i := len(matches) - 1; i >= 0; i-- {
+			if current.after(matches[i]) || (inclusive && matches[i] == current) {
+				target = matches[i]
+				break
+			}
+		}
+	}
+
+	m.listCursor, m.itemCursor = target.list, target.item
+	return true
+}
+
+// after reports whether p is displayed after other
+func (p position) after(other position) bool {
+	return p.list > other.list || (p.list == other.list && p.item > other.item)
+}
+
+// searchStatus describes the state of the search being typed, like
+// "match 2/5" or "no matches"
+func (m Model) searchStatus() string {
+	re, err := compileSearch(m.searchInput.Value())
+	if errors.Is(err, errNoPattern) {
+		return ""
+	}
+	if err != nil {
+		return "invalid pattern"
+	}
+
+	matches := m.searchMatches(re)
+	for i, match := range matches {
+		if match.list == m.listCursor && match.item == m.itemCursor {
+			return fmt.Sprintf("match %d/%d", i+1, len(matches))
+		}
+	}
+	return "no matches"
+}
+
+// highlightMatches renders text with style, highlighting the matches of re
+func (m Model) highlightMatches(text string, style lipgloss.Style, re *regexp.Regexp) string {
+	locs := re.FindAllStringIndex(text, -1)
+	if len(locs) == 0 {
+		return style.Render(text)
+	}
+
+	// Item styles carry padding, which must surround the whole text only
+	left, right := style.GetPaddingLeft(), style.GetPaddingRight()
+	plain := style.UnsetPadding()
+
+	var b strings.Builder
+	b.WriteString(strings.Repeat(" ", left))
+	last := 0
+	for _, loc := range locs {
+		if loc[0] == loc[1] {
+			continue // Empty matches of regular expressions highlight nothing
+		}
+		if loc[0] > last {
+			b.WriteString(plain.Render(text[last:loc[0]]))
+		}
+		b.WriteString(m.styles.SearchMatch.Render(text[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	if last < len(text) {
+		b.WriteString(plain.Render(text[last:]))
+	}
+	b.WriteString(strings.Repeat(" ", right))
+	return b.String()
+}
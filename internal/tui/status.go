@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"tada/internal/todo"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// statusTimeout is how long feedback messages stay on screen
+// Errors stay until the next message or esc.
+const statusTimeout = 3 * time.Second
+
+// status is a message shown below the mode line
+type status struct {
+	text    string
+	isError bool
+	id      int // Tells a message apart from the one that replaced it
+}
+
+// clearStatusMsg clears the status message with the given id
+type clearStatusMsg struct {
+	id int
+}
+
+// opVerbs describe what an operation did, for feedback messages
+var opVerbs = map[string]string{
+	todo.OpAdd:      "added",
+	todo.OpEdit:     "updated",
+	todo.OpComplete: "completed",
+	todo.OpDelete:   "deleted",
+	todo.OpPriority: "prioritized",
+	todo.OpArchive:  "archived",
+	todo.OpRestore:  "restored",
+}
+
+// setStatus shows a feedback message that disappears after a while
+func (m *Model) setStatus(format string, args ...any) tea.Cmd {
+	m.status = status{text: fmt.Sprintf(format, args...), id: m.status.id + 1}
+	id := m.status.id
+	return tea.Tick(statusTimeout, func(time.Time) tea.Msg {
+		return clearStatusMsg{id: id}
+	})
+}
+
+// setError shows an error message, it stays until the next message
+func (m *Model) setError(err error) tea.Cmd {
+	m.status = status{text: err.Error(), isError: true, id: m.status.id + 1}
+	return nil
+}
+
+// saveFailed reports a failed save, the changes stay in memory. A journal
+// that could not be written is reported as it is, todo.txt was saved.
+func (m *Model) saveFailed(err error) tea.Cmd {
+	if errors.Is(err, todo.ErrJournal) {
+		return m.setError(err)
+	}
+	return m.setError(fmt.Errorf("not saved: %w, :write to retry", err))
+}
+
+// clearStatus removes the status message if it is still the one with id
+func (m *Model) clearStatus(id int) {
+	if m.status.id == id && !m.status.isError {
+		m.status.text = ""
+	}
+}
+
+// dismissStatus removes the status message, errors included
+func (m *Model) dismissStatus() {
+	m.status.text = ""
+	m.status.isError = false
+}
+
+// tasks returns "1 task" or "n tasks"
+func tasks(n int) string {
+	if n == 1 {
+		return "1 task"
+	}
+	return fmt.Sprintf("%d tasks", n)
+}
@@ -1,11 +1,17 @@
 package tui
 
 import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
 	"github.com/charmbracelet/lipgloss"
 )
 
 // Theme holds all color and style configurations
-// This structure makes it easy to load colors from a config file in the future
+// Colors can be loaded from the config file with LoadTheme.
 type Theme struct {
 	// Colors
 	Primary        lipgloss.Color
@@ -26,10 +32,47 @@ type Theme struct {
 	InsertModeColor  lipgloss.Color
 	CommandModeColor lipgloss.Color
 	VisualModeColor  lipgloss.Color
+	ModeText         lipgloss.Color // Text on the mode indicator and the unsaved badge
+
+	// Due date colors
+	DueOverdue lipgloss.Color
+	DueToday   lipgloss.Color
+	DueSoon    lipgloss.Color
+	DueLater   lipgloss.Color
+
+	// Search
+	SearchMatch     lipgloss.Color
+	SearchMatchText lipgloss.Color
+
+	// Priority badge backgrounds and their text
+	PriorityA       lipgloss.Color
+	PriorityB       lipgloss.Color
+	PriorityC       lipgloss.Color
+	PriorityLow     lipgloss.Color
+	PriorityText    lipgloss.Color // Text on the A, B and C badges
+	PriorityLowText lipgloss.Color
+}
+
+// DefaultPreset is the theme used when the config doesn't name one
+const DefaultPreset = "dark"
+
+// presets are the bundled themes by name
+var presets = map[string]func() Theme{
+	"dark":  DefaultTheme,
+	"light": LightTheme,
+}
+
+// Presets returns the names of the bundled themes
+func Presets() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
-// DefaultTheme returns the default color scheme
-// In the future, this could be replaced with LoadThemeFromConfig()
+// DefaultTheme returns the default color scheme, for dark terminals
 func DefaultTheme() Theme {
 	return Theme{
 		Primary:        lipgloss.Color("39"),  // Bright blue
@@ -49,7 +92,150 @@ func DefaultTheme() Theme {
 		InsertModeColor:  lipgloss.Color("42"),  // Green
 		CommandModeColor: lipgloss.Color("214"), // Orange
 		VisualModeColor:  lipgloss.Color("170"), // Purple
+		ModeText:         lipgloss.Color("0"),   // Black
+
+		DueOverdue: lipgloss.Color("196"), // Red
+		DueToday:   lipgloss.Color("214"), // Orange
+		DueSoon:    lipgloss.Color("227"), // Yellow
+		DueLater:   lipgloss.Color("245"), // Light gray
+
+		SearchMatch:     lipgloss.Color("220"), // Gold
+		SearchMatchText: lipgloss.Color("0"),   // Black
+
+		PriorityA:       lipgloss.Color("196"), // Red
+		PriorityB:       lipgloss.Color("214"), // Orange
+		PriorityC:       lipgloss.Color("117"), // Light blue
+		PriorityLow:     lipgloss.Color("244"), // Grey
+		PriorityText:    lipgloss.Color("0"),   // Black
+		PriorityLowText: lipgloss.Color("255"), // White
+	}
+}
+
+// LightTheme returns a color scheme for light terminals
+func LightTheme() Theme {
+	return Theme{
+		Primary:        lipgloss.Color("25"),  // Dark blue
+		Secondary:      lipgloss.Color("91"),  // Dark purple
+		Accent:         lipgloss.Color("161"), // Deep pink
+		Success:        lipgloss.Color("28"),  // Dark green
+		Warning:        lipgloss.Color("166"), // Dark orange
+		Danger:         lipgloss.Color("160"), // Red
+		Muted:          lipgloss.Color("244"), // Gray
+		Background:     lipgloss.Color("255"), // White
+		Foreground:     lipgloss.Color("235"), // Near black
+		Border:         lipgloss.Color("250"), // Light gray
+		SelectedBorder: lipgloss.Color("25"),  // Dark blue
+		CompletedText:  lipgloss.Color("247"), // Gray for completed items
+
+		NormalModeColor:  lipgloss.Color("33"),  // Blue
+		InsertModeColor:  lipgloss.Color("35"),  // Green
+		CommandModeColor: lipgloss.Color("208"), // Orange
+		VisualModeColor:  lipgloss.Color("134"), // Purple
+		ModeText:         lipgloss.Color("0"),   // Black
+
+		DueOverdue: lipgloss.Color("160"), // Red
+		DueToday:   lipgloss.Color("166"), // Dark orange
+		DueSoon:    lipgloss.Color("136"), // Dark yellow
+		DueLater:   lipgloss.Color("243"), // Gray
+
+		SearchMatch:     lipgloss.Color("227"), // Yellow
+		SearchMatchText: lipgloss.Color("0"),   // Black
+
+		PriorityA:       lipgloss.Color("203"), // Light red
+		PriorityB:       lipgloss.Color("215"), // Light orange
+		PriorityC:       lipgloss.Color("117"), // Light blue
+		PriorityLow:     lipgloss.Color("250"), // Light grey
+		PriorityText:    lipgloss.Color("0"),   // Black
+		PriorityLowText: lipgloss.Color("235"), // Near black
+	}
+}
+
+// colors maps the color names used in the config file to the theme fields
+func (t *Theme) colors() map[string]*lipgloss.Color {
+	return map[string]*lipgloss.Color{
+		"primary":           &t.Primary,
+		"secondary":         &t.Secondary,
+		"accent":            &t.Accent,
+		"success":           &t.Success,
+		"warning":           &t.Warning,
+		"danger":            &t.Danger,
+		"muted":             &t.Muted,
+		"background":        &t.Background,
+		"foreground":        &t.Foreground,
+		"border":            &t.Border,
+		"selected_border":   &t.SelectedBorder,
+		"completed_text":    &t.CompletedText,
+		"normal_mode":       &t.NormalModeColor,
+		"insert_mode":       &t.InsertModeColor,
+		"command_mode":      &t.CommandModeColor,
+		"visual_mode":       &t.VisualModeColor,
+		"mode_text":         &t.ModeText,
+		"due_overdue":       &t.DueOverdue,
+		"due_today":         &t.DueToday,
+		"due_soon":          &t.DueSoon,
+		"due_later":         &t.DueLater,
+		"search_match":      &t.SearchMatch,
+		"search_match_text": &t.SearchMatchText,
+		"priority_a":        &t.PriorityA,
+		"priority_b":        &t.PriorityB,
+		"priority_c":        &t.PriorityC,
+		"priority_low":      &t.PriorityLow,
+		"priority_text":     &t.PriorityText,
+		"priority_low_text": &t.PriorityLowText,
+	}
+}
+
+// ColorNames returns the names of the colors a theme can override
+func ColorNames() []string {
+	var t Theme
+	names := make([]string, 0, len(t.colors()))
+	for name := range t.colors() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// hexColor matches #rgb and #rrggbb colors
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// parseColor validates an ANSI color number from 0 to 255 or a hex color
+func parseColor(value string) (lipgloss.Color, error) {
+	if hexColor.MatchString(value) {
+		return lipgloss.Color(value), nil
 	}
+	if n, err := strconv.Atoi(value); err == nil && n >= 0 && n <= 255 {
+		return lipgloss.Color(value), nil
+	}
+	return "", fmt.Errorf("invalid color %q, use an ANSI color from 0 to 255 or a hex color like #ff8800", value)
+}
+
+// LoadTheme builds a theme from a preset and per-color overrides, as found
+// in the config file. An empty preset is the default one.
+func LoadTheme(preset string, colors map[string]string) (Theme, error) {
+	if preset == "" {
+		preset = DefaultPreset
+	}
+	newTheme, ok := presets[preset]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %q, use one of: %s", preset, strings.Join(Presets(), ", "))
+	}
+
+	theme := newTheme()
+	fields := theme.colors()
+	for name, value := range colors {
+		field, ok := fields[name]
+		if !ok {
+			return Theme{}, fmt.Errorf("unknown theme color %q, use one of: %s", name, strings.Join(ColorNames(), ", "))
+		}
+		color, err := parseColor(strings.TrimSpace(value))
+		if err != nil {
+			return Theme{}, fmt.Errorf("theme color %s: %w", name, err)
+		}
+		*field = color
+	}
+
+	return theme, nil
 }
 
 // Styles holds all the styled components
@@ -67,6 +253,7 @@ type Styles struct {
 	TodoNormal    lipgloss.Style
 	TodoCompleted lipgloss.Style
 	TodoCursor    lipgloss.Style
+	TodoSelected  lipgloss.Style
 
 	// Priority badges
 	PriorityA         lipgloss.Style
@@ -75,6 +262,20 @@ type Styles struct {
 	PriorityLow       lipgloss.Style
 	PriorityUndefined lipgloss.Style
 
+	// Due date badges
+	DueOverdue lipgloss.Style
+	DueToday   lipgloss.Style
+	DueSoon    lipgloss.Style
+	DueLater   lipgloss.Style
+
+	// Search matches
+	SearchMatch lipgloss.Style
+
+	// Status messages
+	StatusInfo  lipgloss.Style
+	StatusError lipgloss.Style
+	Unsaved     lipgloss.Style
+
 	// Mode indicator
 	ModeNormal  lipgloss.Style
 	ModeInsert  lipgloss.Style
@@ -154,57 +355,95 @@ func NewStyles(theme Theme) Styles {
 			Foreground(theme.Accent).
 			Bold(true),
 
+		TodoSelected: lipgloss.NewStyle().
+			Foreground(theme.VisualModeColor).
+			Bold(true),
+
 		// Priority badges - styled prominently
 		PriorityA: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("0")).
-			Background(lipgloss.Color("196")). // Red
+			Foreground(theme.PriorityText).
+			Background(theme.PriorityA).
 			Padding(0, 1),
 
 		PriorityB: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("0")).
-			Background(lipgloss.Color("214")). // Orange
+			Foreground(theme.PriorityText).
+			Background(theme.PriorityB).
 			Padding(0, 1),
 
 		PriorityC: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("0")).
-			Background(lipgloss.Color("117")). // Light blue
+			Foreground(theme.PriorityText).
+			Background(theme.PriorityC).
 			Padding(0, 1),
 
 		PriorityLow: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("255")).
-			Background(lipgloss.Color("244")). // Grey
+			Foreground(theme.PriorityLowText).
+			Background(theme.PriorityLow).
 			Padding(0, 1),
 
 		PriorityUndefined: lipgloss.NewStyle().
 			Foreground(theme.Muted).
 			Padding(0, 1),
 
+		// Due date badges - colored text, bold when urgent
+		DueOverdue: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(theme.DueOverdue),
+
+		DueToday: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(theme.DueToday),
+
+		DueSoon: lipgloss.NewStyle().
+			Foreground(theme.DueSoon),
+
+		DueLater: lipgloss.NewStyle().
+			Foreground(theme.DueLater),
+
+		// Search matches - highlighted like in vim
+		SearchMatch: lipgloss.NewStyle().
+			Foreground(theme.SearchMatchText).
+			Background(theme.SearchMatch),
+
+		// Status messages - errors stand out until dismissed
+		StatusInfo: lipgloss.NewStyle().
+			Foreground(theme.Success),
+
+		StatusError: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(theme.Danger),
+
+		Unsaved: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(theme.ModeText).
+			Background(theme.Danger).
+			Padding(0, 1),
+
 		// Mode indicators with colored backgrounds
 		ModeNormal: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("0")).
+			Foreground(theme.ModeText).
 			Background(theme.NormalModeColor).
 			Padding(0, 2),
 
 		ModeInsert: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("0")).
+			Foreground(theme.ModeText).
 			Background(theme.InsertModeColor).
 			Padding(0, 2),
 
 		ModeCommand: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("0")).
+			Foreground(theme.ModeText).
 			Background(theme.CommandModeColor).
 			Padding(0, 2),
 
 		ModeVisual: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("0")).
+			Foreground(theme.ModeText).
 			Background(theme.VisualModeColor).
 			Padding(0, 2),
 
@@ -1,374 +1,986 @@
 package tui
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"tada/internal/todo"
 	"testing"
 	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
-func TestPriorityValue(t *testing.T) {
+func TestGetDueState(t *testing.T) {
+	now := time.Date(2025, 10, 15, 12, 0, 0, 0, time.Local)
+
 	tests := []struct {
 		name     string
-		priority string
-		expected int
+		line     string
+		expected dueState
 	}{
 		{
-			name:     "priority A should have value 0",
-			priority: "A",
-			expected: 0,
+			name:     "no due date",
+			line:     "Task",
+			expected: dueNone,
+		},
+		{
+			name:     "invalid due date",
+			line:     "Task due:soon",
+			expected: dueNone,
+		},
+		{
+			name:     "overdue",
+			line:     "Task due:2025-10-14",
+			expected: dueOverdue,
 		},
 		{
-			name:     "priority B should have value 1",
-			priority: "B",
-			expected: 1,
+			name:     "due today",
+			line:     "Task due:2025-10-15",
+			expected: dueToday,
 		},
 		{
-			name:     "priority C should have value 2",
-			priority: "C",
-			expected: 2,
+			name:     "due this week",
+			line:     "Task due:2025-10-21",
+			expected: dueSoon,
 		},
 		{
-			name:     "priority Z should have value 25",
-			priority: "Z",
-			expected: 25,
+			name:     "due later",
+			line:     "Task due:2025-10-22",
+			expected: dueLater,
 		},
 		{
-			name:     "empty priority should have value 1000",
-			priority: "",
-			expected: 1000,
+			name:     "completed overdue task has no due state",
+			line:     "x 2025-10-15 Task due:2025-10-01",
+			expected: dueNone,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := priorityValue(tt.priority)
-			if result != tt.expected {
-				t.Errorf("priorityValue(%q) = %d, want %d", tt.priority, result, tt.expected)
+			if got := getDueState(todo.Parse(tt.line), now); got != tt.expected {
+				t.Errorf("getDueState(%q) = %v, want %v", tt.line, got, tt.expected)
 			}
 		})
 	}
 }
 
-func TestSortTodosByPriority(t *testing.T) {
+func TestCheckFile_ReloadsExternalChanges(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "todo.txt")
+	if err := os.WriteFile(tmpFile, []byte("First task @Work\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	m := NewModel(tmpFile, Options{})
+
+	// Unchanged file is left alone
+	m.checkFile()
+	if len(m.todos) != 1 {
+		t.Fatalf("Expected 1 todo, got %d", len(m.todos))
+	}
+
+	// Edit made by another program
+	if err := os.WriteFile(tmpFile, []byte("First task @Work\nSecond task @Home\n"), 0644); err != nil {
+		t.Fatalf("Failed to update test file: %v", err)
+	}
+
+	m.checkFile()
+	if len(m.todos) != 2 {
+		t.Fatalf("Expected external change to be reloaded, got %d todos", len(m.todos))
+	}
+	if len(m.groups) != 2 {
+		t.Errorf("Expected groups to be refreshed, got %d lists", len(m.groups))
+	}
+	if m.conflict {
+		t.Error("Reload without local changes should not be a conflict")
+	}
+}
+
+func TestSave_ConflictKeepsExternalChanges(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "todo.txt")
+	if err := os.WriteFile(tmpFile, []byte("First task\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	m := NewModel(tmpFile, Options{})
+
+	// Another program edits the file before tada saves its own change
+	external := "First task\nAdded elsewhere\n"
+	if err := os.WriteFile(tmpFile, []byte(external), 0644); err != nil {
+		t.Fatalf("Failed to update test file: %v", err)
+	}
+
+	m.todos = append(m.todos, todo.Parse("Added in tada"))
+	if err := m.save(); err != errConflict {
+		t.Fatalf("save() error = %v, want %v", err, errConflict)
+	}
+	if !m.conflict || !m.dirty {
+		t.Error("Expected model to be dirty and in conflict")
+	}
+
+	content, err := os.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	if string(content) != external {
+		t.Errorf("External change was overwritten: %q", content)
+	}
+
+	// Polling must not silently drop the local change
+	m.checkFile()
+	if len(m.todos) != 2 || m.todos[1].Description != "Added in tada" {
+		t.Errorf("Local change was dropped: %v", m.todos)
+	}
+
+	// :write resolves the conflict in favour of the in-memory list
+	if err := m.write(); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+	if m.conflict || m.dirty {
+		t.Error("Expected conflict to be resolved after write")
+	}
+	content, _ = os.ReadFile(tmpFile)
+	if string(content) != "First task\nAdded in tada\n" {
+		t.Errorf("File content after write = %q", content)
+	}
+}
+
+func TestUndoRedo(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "todo.txt")
+	if err := os.WriteFile(tmpFile, []byte("First task\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	m := NewModel(tmpFile, Options{})
+	m, _ = m.cmdAdd("Second task")
+	m, _ = m.cmdAdd("Third task")
+
+	readFile := func() string {
+		content, err := os.ReadFile(tmpFile)
+		if err != nil {
+			t.Fatalf("Failed to read test file: %v", err)
+		}
+		return string(content)
+	}
+
+	// Undo restores the list and the file one change at a time
+	if label, err := m.undo(); err != nil || label != "add" {
+		t.Fatalf("undo() = %q, %v", label, err)
+	}
+	if got := readFile(); got != "First task\nSecond task\n" {
+		t.Errorf("File after first undo = %q", got)
+	}
+	if _, err := m.undo(); err != nil {
+		t.Fatalf("undo() error = %v", err)
+	}
+	if got := readFile(); got != "First task\n" {
+		t.Errorf("File after second undo = %q", got)
+	}
+	if _, err := m.undo(); err != errNothingToUndo {
+		t.Errorf("undo() error = %v, want %v", err, errNothingToUndo)
+	}
+
+	// Redo applies them again
+	if _, err := m.redo(); err != nil {
+		t.Fatalf("redo() error = %v", err)
+	}
+	if got := readFile(); got != "First task\nSecond task\n" {
+		t.Errorf("File after redo = %q", got)
+	}
+
+	// A new change drops the redo history
+	m, _ = m.cmdAdd("Other task")
+	if _, err := m.redo(); err != errNothingToRedo {
+		t.Errorf("redo() error = %v, want %v", err, errNothingToRedo)
+	}
+	if len(m.todos) != 3 {
+		t.Errorf("Expected 3 todos, got %d", len(m.todos))
+	}
+}
+
+func TestUndo_Archive(t *testing.T) {
+	dir := t.TempDir()
+	tmpFile := filepath.Join(dir, "todo.txt")
+	content := "x 2020-01-05 Old task\nOpen task\n"
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	archiveFile := filepath.Join(dir, "todo_archive_2020_01.txt")
+
+	m := NewModel(tmpFile, Options{})
+	m, _ = m.cmdArchive("")
+	if len(m.todos) != 1 {
+		t.Fatalf("Expected 1 todo after archive, got %d", len(m.todos))
+	}
+	if _, err := os.Stat(archiveFile); err != nil {
+		t.Fatalf("Expected archive file: %v", err)
+	}
+
+	// Undo moves the task back out of the archive
+	if _, err := m.undo(); err != nil {
+		t.Fatalf("undo() error = %v", err)
+	}
+	if got, _ := os.ReadFile(tmpFile); string(got) != content {
+		t.Errorf("File after undo = %q, want %q", got, content)
+	}
+	if _, err := os.Stat(archiveFile); !os.IsNotExist(err) {
+		t.Errorf("Expected empty archive file to be removed, got %v", err)
+	}
+
+	// Redo archives it again
+	if _, err := m.redo(); err != nil {
+		t.Fatalf("redo() error = %v", err)
+	}
+	if got, _ := os.ReadFile(archiveFile); string(got) != "x 2020-01-05 Old task\n" {
+		t.Errorf("Archive after redo = %q", got)
+	}
+}
+
+func TestUndo_ArchiveFailureKeepsHistory(t *testing.T) {
+	dir := t.TempDir()
+	tmpFile := filepath.Join(dir, "todo.txt")
+	if err := os.WriteFile(tmpFile, []byte("x 2020-01-05 Old task\nOpen task\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	archiveFile := filepath.Join(dir, "todo_archive_2020_01.txt")
+
+	m := NewModel(tmpFile, Options{})
+	m, _ = m.cmdArchive("")
+	if _, err := m.undo(); err != nil {
+		t.Fatalf("undo() error = %v", err)
+	}
+
+	// A directory in place of the archive file makes redo fail
+	if err := os.Mkdir(archiveFile, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if _, err := m.redo(); err == nil {
+		t.Fatal("Expected redo to fail")
+	}
+	if len(m.history.redo) != 1 || len(m.history.undo) != 0 || len(m.todos) != 2 {
+		t.Errorf("Expected a failed redo to change nothing, got %d redo, %d undo and %d todos",
+			len(m.history.redo), len(m.history.undo), len(m.todos))
+	}
+
+	// Once the archive can be written the change can be redone
+	if err := os.Remove(archiveFile); err != nil {
+		t.Fatalf("Failed to remove directory: %v", err)
+	}
+	if _, err := m.redo(); err != nil {
+		t.Fatalf("redo() error = %v", err)
+	}
+	if len(m.history.undo) != 1 || len(m.todos) != 1 {
+		t.Errorf("Expected the archive to be redone, got %d undo and %d todos", len(m.history.undo), len(m.todos))
+	}
+}
+
+func TestArchive_SaveFailureKeepsArchiveFiles(t *testing.T) {
+	dir := t.TempDir()
+	tmpFile := filepath.Join(dir, "todo.txt")
+	if err := os.WriteFile(tmpFile, []byte("x 2020-01-05 Old task\nOpen task\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	archiveFile := filepath.Join(dir, "todo_archive_2020_01.txt")
+
+	m := NewModel(tmpFile, Options{})
+
+	// Another program changes todo.txt, so the save is refused
+	if err := os.WriteFile(tmpFile, []byte("x 2020-01-05 Old task\nOpen task\nAdded elsewhere\n"), 0644); err != nil {
+		t.Fatalf("Failed to change test file: %v", err)
+	}
+	m, _ = m.cmdArchive("")
+
+	if !m.status.isError || !strings.HasPrefix(m.status.text, "archive failed") {
+		t.Errorf("Expected the archive to fail, got %+v", m.status)
+	}
+	if _, err := os.Stat(archiveFile); !os.IsNotExist(err) {
+		t.Errorf("Expected no archive file after a failed save, got %v", err)
+	}
+	if len(m.todos) != 2 || len(m.history.undo) != 0 || len(m.pendingJournal) != 0 || m.dirty {
+		t.Errorf("Expected a failed archive to change nothing, got %d todos, %d undo, %d journal entries, dirty %v",
+			len(m.todos), len(m.history.undo), len(m.pendingJournal), m.dirty)
+	}
+}
+
+func TestUndo_SaveFailureKeepsArchive(t *testing.T) {
+	dir := t.TempDir()
+	tmpFile := filepath.Join(dir, "todo.txt")
+	if err := os.WriteFile(tmpFile, []byte("x 2020-01-05 Old task\nOpen task\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	archiveFile := filepath.Join(dir, "todo_archive_2020_01.txt")
+
+	m := NewModel(tmpFile, Options{})
+	m, _ = m.cmdArchive("")
+
+	// Another program changes todo.txt, so the save is refused
+	if err := os.WriteFile(tmpFile, []byte("Open task\nAdded elsewhere\n"), 0644); err != nil {
+		t.Fatalf("Failed to change test file: %v", err)
+	}
+	if _, err := m.undo(); err == nil {
+		t.Fatal("Expected undo to fail")
+	}
+
+	if got, _ := os.ReadFile(archiveFile); string(got) != "x 2020-01-05 Old task\n" {
+		t.Errorf("Archive after failed undo = %q, want it unchanged", got)
+	}
+	if len(m.history.undo) != 1 || len(m.history.redo) != 0 || len(m.todos) != 1 || m.dirty {
+		t.Errorf("Expected a failed undo to change nothing, got %d undo, %d redo, %d todos, dirty %v",
+			len(m.history.undo), len(m.history.redo), len(m.todos), m.dirty)
+	}
+}
+
+func TestSave_WritesJournal(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "todo.txt")
+	if err := os.WriteFile(tmpFile, []byte("First task\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	m := NewModel(tmpFile, Options{})
+	m, _ = m.cmdAdd("Second task")
+	if _, err := m.undo(); err != nil {
+		t.Fatalf("undo() error = %v", err)
+	}
+
+	entries, err := todo.LoadJournal(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadJournal() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Op != todo.OpAdd || entries[1].Op != todo.OpUndo {
+		t.Fatalf("Unexpected journal %+v", entries)
+	}
+	if entries[1].Changes[0].Before != "Second task" {
+		t.Errorf("Undo should record the removed line, got %+v", entries[1].Changes)
+	}
+	if len(m.pendingJournal) != 0 {
+		t.Errorf("Expected pending journal entries to be written, got %d", len(m.pendingJournal))
+	}
+}
+
+func TestSave_JournalFailureIsNotASaveFailure(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "todo.txt")
+	if err := os.WriteFile(tmpFile, []byte("First task\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	// A directory in place of the journal can't be written
+	if err := os.Mkdir(todo.JournalPath(tmpFile), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	m := NewModel(tmpFile, Options{})
+	m, _ = m.cmdAdd("Second task")
+	if m.dirty {
+		t.Error("Expected the todos to be saved")
+	}
+	if !m.status.isError || strings.Contains(m.status.text, "not saved") {
+		t.Errorf("Expected a journal warning, got %q", m.status.text)
+	}
+	content, _ := os.ReadFile(tmpFile)
+	if string(content) != "First task\nSecond task\n" {
+		t.Errorf("File after add = %q", content)
+	}
+}
+
+func TestVisualMode_BatchActions(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "todo.txt")
+	content := "First @Work\nSecond @Work\nThird @Work\nFourth @Home\n"
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	m := NewModel(tmpFile, Options{})
+	m.listCursor = 1 // @Work, after @Home
+
+	// Select the first two items of the list
+	model, _ := m.handleNormalMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")})
+	model, _ = model.(Model).handleVisualMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	m = model.(Model)
+	if !m.isSelected(1, 0) || !m.isSelected(1, 1) || m.isSelected(1, 2) {
+		t.Fatal("Expected the first two items of the list to be selected")
+	}
+
+	// Selection never leaves the current list
+	for i := 0; i < 5; i++ {
+		model, _ = m.handleVisualMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+		m = model.(Model)
+	}
+	if len(m.selection()) != 3 {
+		t.Errorf("Expected 3 selected items, got %d", len(m.selection()))
+	}
+
+	// Set the priority of all of them in one step
+	model, _ = m.handleVisualMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	model, _ = model.(Model).handleVisualMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	m = model.(Model)
+	if m.mode != ModeNormal || m.visual {
+		t.Error("Expected batch action to return to normal mode")
+	}
+	got, _ := os.ReadFile(tmpFile)
+	if string(got) != "(B) First @Work\n(B) Second @Work\n(B) Third @Work\nFourth @Home\n" {
+		t.Errorf("File after batch priority = %q", got)
+	}
+
+	// One undo reverts the whole batch
+	if _, err := m.undo(); err != nil {
+		t.Fatalf("undo() error = %v", err)
+	}
+	if got, _ := os.ReadFile(tmpFile); string(got) != content {
+		t.Errorf("File after undo = %q", got)
+	}
+
+	// Tag and delete a selection through command mode
+	m.refreshGroups()
+	m.itemCursor = 0
+	model, _ = m.handleNormalMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")})
+	model, _ = model.(Model).handleVisualMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	m = model.(Model)
+	m, _ = m.cmdTag("+Q4")
+	if got, _ := os.ReadFile(tmpFile); string(got) != "First @Work +Q4\nSecond @Work +Q4\nThird @Work\nFourth @Home\n" {
+		t.Errorf("File after tag = %q", got)
+	}
+
+	m.itemCursor = 0
+	model, _ = m.handleNormalMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")})
+	model, _ = model.(Model).handleVisualMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	model, _ = model.(Model).handleVisualMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m = model.(Model)
+	if got, _ := os.ReadFile(tmpFile); string(got) != "\n\nThird @Work\nFourth @Home\n" {
+		t.Errorf("File after deleting the selection = %q", got)
+	}
+}
+
+func TestCompileSearch(t *testing.T) {
 	tests := []struct {
-		name     string
-		todos    []TodoWithIndex
-		expected []string // Expected order of priorities
+		pattern string
+		text    string
+		match   bool
+		wantErr bool
 	}{
-		{
-			name: "sort A, C, B should result in A, B, C",
-			todos: []TodoWithIndex{
-				{Item: todo.Item{Priority: "A", Description: "Task A"}},
-				{Item: todo.Item{Priority: "C", Description: "Task C"}},
-				{Item: todo.Item{Priority: "B", Description: "Task B"}},
-			},
-			expected: []string{"A", "B", "C"},
-		},
-		{
-			name: "sort with empty priority last",
-			todos: []TodoWithIndex{
-				{Item: todo.Item{Priority: "", Description: "No priority"}},
-				{Item: todo.Item{Priority: "B", Description: "Task B"}},
-				{Item: todo.Item{Priority: "A", Description: "Task A"}},
-			},
-			expected: []string{"A", "B", ""},
-		},
-		{
-			name: "already sorted list remains sorted",
-			todos: []TodoWithIndex{
-				{Item: todo.Item{Priority: "A", Description: "Task A"}},
-				{Item: todo.Item{Priority: "B", Description: "Task B"}},
-				{Item: todo.Item{Priority: "C", Description: "Task C"}},
-			},
-			expected: []string{"A", "B", "C"},
-		},
-		{
-			name: "reverse sorted list gets sorted correctly",
-			todos: []TodoWithIndex{
-				{Item: todo.Item{Priority: "Z", Description: "Task Z"}},
-				{Item: todo.Item{Priority: "B", Description: "Task B"}},
-				{Item: todo.Item{Priority: "A", Description: "Task A"}},
-			},
-			expected: []string{"A", "B", "Z"},
-		},
-		{
-			name: "single item remains unchanged",
-			todos: []TodoWithIndex{
-				{Item: todo.Item{Priority: "A", Description: "Task A"}},
-			},
-			expected: []string{"A"},
-		},
-		{
-			name:     "empty list remains empty",
-			todos:    []TodoWithIndex{},
-			expected: []string{},
-		},
+		{pattern: "dentist", text: "Call Dentist", match: true},
+		{pattern: "Dentist", text: "call dentist", match: false},
+		{pattern: `Dentist\c`, text: "call dentist", match: true},
+		{pattern: `dentist\C`, text: "Call Dentist", match: false},
+		{pattern: "a.c", text: "abc", match: false},
+		{pattern: "a.c", text: "a.c", match: true},
+		{pattern: `\vcall|write`, text: "Write report", match: true},
+		{pattern: `\v(`, wantErr: true},
+		{pattern: "", wantErr: true},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			sortTodosByPriority(tt.todos)
-
-			if len(tt.todos) != len(tt.expected) {
-				t.Fatalf("Expected %d todos, got %d", len(tt.expected), len(tt.todos))
+		t.Run(tt.pattern, func(t *testing.T) {
+			re, err := compileSearch(tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("compileSearch(%q) error = %v, wantErr %v", tt.pattern, err, tt.wantErr)
 			}
-
-			for i, expectedPriority := range tt.expected {
-				if tt.todos[i].Item.Priority != expectedPriority {
-					t.Errorf("todos[%d].Priority = %q, want %q", i, tt.todos[i].Item.Priority, expectedPriority)
-				}
+			if err == nil && re.MatchString(tt.text) != tt.match {
+				t.Errorf("compileSearch(%q) matching %q = %v, want %v", tt.pattern, tt.text, !tt.match, tt.match)
 			}
 		})
 	}
 }
 
-func TestGroupTodosByContext(t *testing.T) {
-	tests := []struct {
-		name             string
-		todos            []todo.Item
-		expectedContexts []string // Expected context names in order
-		expectedCounts   map[string]int
-	}{
-		{
-			name: "single context",
-			todos: []todo.Item{
-				{Description: "Task 1 @Work", Contexts: []string{"Work"}},
-				{Description: "Task 2 @Work", Contexts: []string{"Work"}},
-			},
-			expectedContexts: []string{"Work"},
-			expectedCounts: map[string]int{
-				"Work": 2,
-			},
-		},
-		{
-			name: "multiple contexts",
-			todos: []todo.Item{
-				{Description: "Task 1 @Work", Contexts: []string{"Work"}},
-				{Description: "Task 2 @Personal", Contexts: []string{"Personal"}},
-				{Description: "Task 3 @Work", Contexts: []string{"Work"}},
-			},
-			expectedContexts: []string{"Personal", "Work"},
-			expectedCounts: map[string]int{
-				"Work":     2,
-				"Personal": 1,
-			},
-		},
-		{
-			name: "no context items go to No Context",
-			todos: []todo.Item{
-				{Description: "Task without context", Contexts: []string{}},
-				{Description: "Another task", Contexts: []string{}},
-			},
-			expectedContexts: []string{"No Context"},
-			expectedCounts: map[string]int{
-				"No Context": 2,
-			},
-		},
-		{
-			name: "mixed context and no context",
-			todos: []todo.Item{
-				{Description: "Task @Work", Contexts: []string{"Work"}},
-				{Description: "Task without context", Contexts: []string{}},
-			},
-			expectedContexts: []string{"No Context", "Work"},
-			expectedCounts: map[string]int{
-				"No Context": 1,
-				"Work":       1,
-			},
-		},
-		{
-			name: "todo with multiple contexts appears in both",
-			todos: []todo.Item{
-				{Description: "Task @Work @Office", Contexts: []string{"Work", "Office"}},
-			},
-			expectedContexts: []string{"Office", "Work"},
-			expectedCounts: map[string]int{
-				"Office": 1,
-				"Work":   1,
-			},
-		},
-		{
-			name: "contexts sorted alphabetically",
-			todos: []todo.Item{
-				{Description: "Task @Zebra", Contexts: []string{"Zebra"}},
-				{Description: "Task @Apple", Contexts: []string{"Apple"}},
-				{Description: "Task @Mango", Contexts: []string{"Mango"}},
-			},
-			expectedContexts: []string{"Apple", "Mango", "Zebra"},
-			expectedCounts: map[string]int{
-				"Zebra": 1,
-				"Apple": 1,
-				"Mango": 1,
-			},
-		},
-		{
-			name: "old completed items are filtered out",
-			todos: []todo.Item{
-				{
-					Description:    "Old completed @Work",
-					Contexts:       []string{"Work"},
-					Completed:      true,
-					CompletionDate: time.Now().AddDate(0, 0, -10).Format("2006-01-02"),
-				},
-				{Description: "Active task @Work", Contexts: []string{"Work"}},
-			},
-			expectedContexts: []string{"Work"},
-			expectedCounts: map[string]int{
-				"Work": 1,
-			},
-		},
-		{
-			name:             "empty todo list",
-			todos:            []todo.Item{},
-			expectedContexts: []string{},
-			expectedCounts:   map[string]int{},
-		},
+func TestSearch_NextAndPrevious(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "todo.txt")
+	content := "Call mom @Home\nWrite report @Work\nCall dentist @Work\nCall plumber\n"
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := groupTodosByContext(tt.todos)
+	// Lists: No Context, @Home, @Work
+	m := NewModel(tmpFile, Options{})
+	model, _ := m.startSearch()
+	for _, r := range "call" {
+		model, _ = model.(Model).handleSearchMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	model, _ = model.(Model).handleSearchMode(tea.KeyMsg{Type: tea.KeyEnter})
+	m = model.(Model)
+	if m.mode != ModeNormal || m.searchPattern != "call" {
+		t.Fatalf("Expected confirmed search, got mode %v pattern %q", m.mode, m.searchPattern)
+	}
 
-			// Check number of context groups
-			if len(result) != len(tt.expectedContexts) {
-				t.Errorf("Expected %d context groups, got %d", len(tt.expectedContexts), len(result))
-			}
+	cursor := func() position { return position{list: m.listCursor, item: m.itemCursor} }
+	if got := cursor(); got != (position{0, 0}) {
+		t.Errorf("First match at %v, want {0 0}", got)
+	}
 
-			// Check context names and order
-			for i, expectedContext := range tt.expectedContexts {
-				if i >= len(result) {
-					t.Errorf("Missing context at index %d: expected %q", i, expectedContext)
-					continue
-				}
-				if result[i].Context != expectedContext {
-					t.Errorf("Context[%d] = %q, want %q", i, result[i].Context, expectedContext)
-				}
-			}
+	model, _ = m.searchNext(true)
+	m = model.(Model)
+	if got := cursor(); got != (position{1, 0}) {
+		t.Errorf("n moved to %v, want {1 0}", got)
+	}
 
-			// Check counts per context
-			for _, contextList := range result {
-				expectedCount, exists := tt.expectedCounts[contextList.Context]
-				if !exists {
-					t.Errorf("Unexpected context: %q", contextList.Context)
-					continue
-				}
-				if len(contextList.Todos) != expectedCount {
-					t.Errorf("Context %q has %d todos, want %d", contextList.Context, len(contextList.Todos), expectedCount)
-				}
-			}
-		})
+	// Write report is skipped
+	model, _ = m.searchNext(true)
+	m = model.(Model)
+	if got := cursor(); got != (position{2, 1}) {
+		t.Errorf("n moved to %v, want {2 1}", got)
+	}
+
+	// Wraps around at the end, N goes back
+	model, _ = m.searchNext(true)
+	m = model.(Model)
+	if got := cursor(); got != (position{0, 0}) {
+		t.Errorf("n should wrap to {0 0}, got %v", got)
+	}
+	model, _ = m.searchNext(false)
+	m = model.(Model)
+	if got := cursor(); got != (position{2, 1}) {
+		t.Errorf("N moved to %v, want {2 1}", got)
+	}
+
+	// Esc restores the cursor
+	model, _ = m.startSearch()
+	model, _ = model.(Model).handleSearchMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")})
+	model, _ = model.(Model).handleSearchMode(tea.KeyMsg{Type: tea.KeyEsc})
+	m = model.(Model)
+	if got := cursor(); got != (position{2, 1}) {
+		t.Errorf("Esc should restore the cursor to {2 1}, got %v", got)
+	}
+	if m.searchPattern != "call" {
+		t.Errorf("Esc should keep the previous pattern, got %q", m.searchPattern)
 	}
 }
 
-func TestGroupTodosByContext_PrioritySorting(t *testing.T) {
-	todos := []todo.Item{
-		{Description: "Task C @Work", Priority: "C", Contexts: []string{"Work"}},
-		{Description: "Task A @Work", Priority: "A", Contexts: []string{"Work"}},
-		{Description: "Task B @Work", Priority: "B", Contexts: []string{"Work"}},
+func TestCmdFilter(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "todo.txt")
+	content := "(A) Report @Work\n(C) Slides @Work\nCall mom @Home\n"
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	m := NewModel(tmpFile, Options{})
+	m, _ = m.cmdFilter("@work pri:<=B")
+	if len(m.groups) != 1 || len(m.groups[0].Todos) != 1 {
+		t.Fatalf("Expected one matching todo, got %+v", m.groups)
+	}
+	if got := m.groups[0].Todos[0].Item.Description; got != "Report @Work" {
+		t.Errorf("Filtered todo = %q", got)
 	}
 
-	result := groupTodosByContext(todos)
+	// Invalid queries keep the current filter
+	m, _ = m.cmdFilter("pri:1")
+	if m.filter.String() != "@work pri:<=B" {
+		t.Errorf("Invalid query replaced the filter with %q", m.filter.String())
+	}
 
-	if len(result) != 1 {
-		t.Fatalf("Expected 1 context group, got %d", len(result))
+	// No query clears the filter
+	m, _ = m.cmdFilter("")
+	if len(m.groups) != 2 {
+		t.Errorf("Expected all lists after clearing the filter, got %d", len(m.groups))
 	}
+}
 
-	workContext := result[0]
-	if workContext.Context != "Work" {
-		t.Fatalf("Expected Work context, got %q", workContext.Context)
+func TestCmdGroup(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "todo.txt")
+	content := "(A) Report @Work +Q4\nSlides @Work +Q4\nCall mom @Home\n"
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
 	}
 
-	// Verify todos are sorted by priority
-	expectedPriorities := []string{"A", "B", "C"}
-	if len(workContext.Todos) != len(expectedPriorities) {
-		t.Fatalf("Expected %d todos, got %d", len(expectedPriorities), len(workContext.Todos))
+	m := NewModel(tmpFile, Options{GroupBy: todo.GroupProject})
+	if len(m.groups) != 2 || m.groups[1].Title != "+Q4" {
+		t.Fatalf("Expected grouping by project, got %+v", m.groups)
 	}
 
-	for i, expectedPriority := range expectedPriorities {
-		if workContext.Todos[i].Item.Priority != expectedPriority {
-			t.Errorf("Todo[%d] priority = %q, want %q", i, workContext.Todos[i].Item.Priority, expectedPriority)
+	m, _ = m.cmdGroup("priority")
+	if m.grouping != todo.GroupPriority || len(m.groups) != 2 || m.groups[0].Title != "Priority A" {
+		t.Errorf("Expected grouping by priority, got %+v", m.groups)
+	}
+
+	// Unknown groupings are ignored, no argument restores the default
+	m, _ = m.cmdGroup("color")
+	if m.grouping != todo.GroupPriority {
+		t.Errorf("Unknown grouping changed the grouping to %q", m.grouping)
+	}
+	m, _ = m.cmdGroup("")
+	if m.grouping != todo.GroupProject {
+		t.Errorf("Expected default grouping, got %q", m.grouping)
+	}
+}
+
+func TestViewport_FollowsCursor(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "todo.txt")
+	var content string
+	for i := 1; i <= 100; i++ {
+		content += fmt.Sprintf("Task %03d @Work\n", i)
+	}
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	update := func(m Model, msg tea.Msg) Model {
+		model, _ := m.Update(msg)
+		return model.(Model)
+	}
+	key := func(m Model, k string) Model {
+		if k == "ctrl+d" {
+			return update(m, tea.KeyMsg{Type: tea.KeyCtrlD})
 		}
+		return update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+	}
+
+	m := update(NewModel(tmpFile, Options{}), tea.WindowSizeMsg{Width: 80, Height: 30})
+	view := m.View()
+	if lines := strings.Count(view, "\n") + 1; lines > 30 {
+		t.Errorf("Expected the view to fit in 30 lines, got %d", lines)
+	}
+	if !strings.Contains(view, "Task 001") || strings.Contains(view, "Task 100") || !strings.Contains(view, "↓") {
+		t.Errorf("Expected the top of the list with a scroll indicator, got:\n%s", view)
+	}
+
+	// G jumps to the last task and scrolls it into view
+	m = key(m, "G")
+	view = m.View()
+	if m.itemCursor != 99 || !strings.Contains(view, "Task 100") || strings.Contains(view, "Task 001") || !strings.Contains(view, "↑") {
+		t.Errorf("Expected the bottom of the list at item 99, cursor at %d:\n%s", m.itemCursor, view)
+	}
+
+	// gg goes back to the top, ctrl+d moves half a page down
+	m = key(key(m, "g"), "g")
+	if m.itemCursor != 0 || m.offset != 0 {
+		t.Errorf("Expected the top after gg, cursor at %d, offset %d", m.itemCursor, m.offset)
+	}
+	m = key(m, "ctrl+d")
+	if m.itemCursor != m.halfPage() || m.itemCursor == 0 {
+		t.Errorf("Expected ctrl+d to move %d items, cursor at %d", m.halfPage(), m.itemCursor)
+	}
+
+	// Messages that change nothing on screen keep the offset as it is
+	m = key(m, "G")
+	offset := m.offset
+	m.offset = -1
+	if m = update(m, clearStatusMsg{id: m.status.id}); m.offset != -1 {
+		t.Errorf("Expected the offset to be kept for an unchanged layout, got %d", m.offset)
+	}
+	if m = update(m, tea.WindowSizeMsg{Width: 80, Height: 30}); m.offset != -1 {
+		t.Errorf("Expected the offset to be kept for the same size, got %d", m.offset)
+	}
+	if m = update(m, tea.WindowSizeMsg{Width: 80, Height: 20}); m.offset <= offset {
+		t.Errorf("Expected a smaller terminal to scroll further than %d, got %d", offset, m.offset)
 	}
 }
 
-func TestSortTodosByPriority_CompletionStatus(t *testing.T) {
-	tests := []struct {
-		name              string
-		todos             []TodoWithIndex
-		expectedCompleted []bool   // Expected completion status in order
-		expectedPriority  []string // Expected priority in order
+func TestStatus_FeedbackAndErrors(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "todo.txt")
+	if err := os.WriteFile(tmpFile, []byte("Task one @Work\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	m := NewModel(tmpFile, Options{})
+	m, cmd := m.cmdAdd("Task two @Work")
+	if m.status.text != "added Task two @Work" || m.status.isError || cmd == nil {
+		t.Errorf("Expected a transient message for the add, got %+v", m.status)
+	}
+
+	// The message goes away, unless another one replaced it
+	model, _ := m.Update(clearStatusMsg{id: m.status.id - 1})
+	if m = model.(Model); m.status.text == "" {
+		t.Error("A stale clear removed the current message")
+	}
+	model, _ = m.Update(clearStatusMsg{id: m.status.id})
+	if m = model.(Model); m.status.text != "" {
+		t.Errorf("Expected the message to be cleared, got %q", m.status.text)
+	}
+
+	m.commandInput.SetValue("frobnicate")
+	m, _ = m.executeCommand()
+	if !m.status.isError || m.status.text != "unknown command: frobnicate" {
+		t.Errorf("Expected an error for an unknown command, got %+v", m.status)
+	}
+
+	// Errors stay until dismissed
+	model, _ = m.Update(clearStatusMsg{id: m.status.id})
+	if m = model.(Model); m.status.text == "" {
+		t.Error("Expected the error to stay")
+	}
+}
+
+func TestStatus_FailedSave(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing", "todo.txt")
+
+	m := NewModel(missing, Options{})
+	m, _ = m.cmdAdd("Task @Work")
+	if !m.status.isError || !strings.HasPrefix(m.status.text, "not saved:") {
+		t.Errorf("Expected a save error, got %+v", m.status)
+	}
+	if !m.dirty || !strings.Contains(m.View(), "UNSAVED") {
+		t.Error("Expected the unsaved changes to be flagged")
+	}
+}
+
+func TestLoadTheme(t *testing.T) {
+	theme, err := LoadTheme("", nil)
+	if err != nil || theme != DefaultTheme() {
+		t.Errorf("Expected the default theme, got %+v, %v", theme, err)
+	}
+
+	// Presets set every color, badge text included
+	for _, preset := range Presets() {
+		theme, err := LoadTheme(preset, nil)
+		if err != nil {
+			t.Fatalf("LoadTheme(%q) error = %v", preset, err)
+		}
+		for name, color := range theme.colors() {
+			if *color == "" {
+				t.Errorf("Preset %q has no %s color", preset, name)
+			}
+		}
+	}
+
+	theme, err = LoadTheme("light", map[string]string{"accent": "#ff8800", "priority_a": "203"})
+	if err != nil {
+		t.Fatalf("LoadTheme failed: %v", err)
+	}
+	if theme.Accent != "#ff8800" || theme.PriorityA != "203" || theme.Background != LightTheme().Background {
+		t.Errorf("Expected the light theme with overrides, got %+v", theme)
+	}
+
+	invalid := []struct {
+		preset string
+		colors map[string]string
 	}{
-		{
-			name: "uncompleted tasks come before completed tasks",
-			todos: []TodoWithIndex{
-				{Item: todo.Item{Priority: "A", Description: "Completed A", Completed: true}},
-				{Item: todo.Item{Priority: "B", Description: "Uncompleted B", Completed: false}},
-				{Item: todo.Item{Priority: "C", Description: "Uncompleted C", Completed: false}},
-			},
-			expectedCompleted: []bool{false, false, true},
-			expectedPriority:  []string{"B", "C", "A"},
-		},
-		{
-			name: "completed tasks sorted by priority within their group",
-			todos: []TodoWithIndex{
-				{Item: todo.Item{Priority: "C", Description: "Completed C", Completed: true}},
-				{Item: todo.Item{Priority: "A", Description: "Completed A", Completed: true}},
-				{Item: todo.Item{Priority: "B", Description: "Completed B", Completed: true}},
-			},
-			expectedCompleted: []bool{true, true, true},
-			expectedPriority:  []string{"A", "B", "C"},
-		},
-		{
-			name: "uncompleted tasks sorted by priority within their group",
-			todos: []TodoWithIndex{
-				{Item: todo.Item{Priority: "C", Description: "Uncompleted C", Completed: false}},
-				{Item: todo.Item{Priority: "A", Description: "Uncompleted A", Completed: false}},
-				{Item: todo.Item{Priority: "B", Description: "Uncompleted B", Completed: false}},
-			},
-			expectedCompleted: []bool{false, false, false},
-			expectedPriority:  []string{"A", "B", "C"},
-		},
-		{
-			name: "mixed priorities with mixed completion status",
-			todos: []TodoWithIndex{
-				{Item: todo.Item{Priority: "C", Description: "Completed C", Completed: true}},
-				{Item: todo.Item{Priority: "A", Description: "Uncompleted A", Completed: false}},
-				{Item: todo.Item{Priority: "B", Description: "Completed B", Completed: true}},
-				{Item: todo.Item{Priority: "", Description: "Uncompleted no priority", Completed: false}},
-				{Item: todo.Item{Priority: "", Description: "Completed no priority", Completed: true}},
-			},
-			expectedCompleted: []bool{false, false, true, true, true},
-			expectedPriority:  []string{"A", "", "B", "C", ""},
-		},
-		{
-			name: "completed with higher priority still comes after uncompleted with lower priority",
-			todos: []TodoWithIndex{
-				{Item: todo.Item{Priority: "A", Description: "Completed A", Completed: true}},
-				{Item: todo.Item{Priority: "Z", Description: "Uncompleted Z", Completed: false}},
-			},
-			expectedCompleted: []bool{false, true},
-			expectedPriority:  []string{"Z", "A"},
-		},
+		{preset: "solarized"},
+		{colors: map[string]string{"sparkle": "1"}},
+		{colors: map[string]string{"accent": "256"}},
+		{colors: map[string]string{"accent": "#ff88"}},
+		{colors: map[string]string{"accent": "pink"}},
+	}
+	for _, tt := range invalid {
+		if _, err := LoadTheme(tt.preset, tt.colors); err == nil {
+			t.Errorf("Expected an error for %q %v", tt.preset, tt.colors)
+		}
+	}
+}
+
+func TestLoadKeymap(t *testing.T) {
+	keymap, err := LoadKeymap(",", map[string]string{
+		"<leader>t": "top",
+		"dd":        "delete",
+		"q":         "none",
+		"<C-j>":     "down",
+	})
+	if err != nil {
+		t.Fatalf("LoadKeymap failed: %v", err)
 	}
 
+	tests := []struct {
+		keys    []string
+		action  Action
+		pending bool
+	}{
+		{keys: []string{","}, pending: true},
+		{keys: []string{",", "t"}, action: ActionTop},
+		{keys: []string{",", "e"}, action: ActionEdit},
+		{keys: []string{" ", "e"}},
+		{keys: []string{"d"}, pending: true},
+		{keys: []string{"d", "d"}, action: ActionDelete},
+		{keys: []string{"q"}},
+		{keys: []string{"ctrl+j"}, action: ActionDown},
+		{keys: []string{"j"}, action: ActionDown},
+	}
 	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			sortTodosByPriority(tt.todos)
+		action, pending := keymap.Lookup(tt.keys)
+		if action != tt.action || pending != tt.pending {
+			t.Errorf("Lookup(%q) = %q, %v, expected %q, %v", tt.keys, action, pending, tt.action, tt.pending)
+		}
+	}
 
-			if len(tt.todos) != len(tt.expectedCompleted) {
-				t.Fatalf("Expected %d todos, got %d", len(tt.expectedCompleted), len(tt.todos))
-			}
+	if help := keymap.Help([]string{","}); !strings.Contains(help, "t=top") || !strings.Contains(help, "e=edit") {
+		t.Errorf("Expected the leader help to list the bindings, got %q", help)
+	}
 
-			for i := range tt.todos {
-				if tt.todos[i].Item.Completed != tt.expectedCompleted[i] {
-					t.Errorf("todos[%d].Completed = %v, want %v (description: %s)",
-						i, tt.todos[i].Item.Completed, tt.expectedCompleted[i], tt.todos[i].Item.Description)
-				}
-				if tt.todos[i].Item.Priority != tt.expectedPriority[i] {
-					t.Errorf("todos[%d].Priority = %q, want %q (description: %s)",
-						i, tt.todos[i].Item.Priority, tt.expectedPriority[i], tt.todos[i].Item.Description)
-				}
-			}
-		})
+	invalid := []map[string]string{
+		{"x": "explode"},
+		{"<hyper-x>": "top"},
+		{"g": "top"}, // Hides gg
+	}
+	for _, overrides := range invalid {
+		if _, err := LoadKeymap("", overrides); err == nil {
+			t.Errorf("Expected an error for %v", overrides)
+		}
+	}
+}
+
+func TestKeymap_Sequences(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "todo.txt")
+	if err := os.WriteFile(tmpFile, []byte("Task one @Work\nTask two @Work\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	keymap, err := LoadKeymap(",", map[string]string{"<leader>j": "down"})
+	if err != nil {
+		t.Fatalf("LoadKeymap failed: %v", err)
+	}
+	m := NewModel(tmpFile, Options{Keymap: &keymap})
+	press := func(k string) {
+		model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		m = model.(Model)
+	}
+
+	press(",")
+	if len(m.pendingKeys) != 1 || !strings.Contains(m.View(), "LEADER") {
+		t.Errorf("Expected to wait for the rest of the sequence, pending %q", m.pendingKeys)
+	}
+	press("j")
+	if m.itemCursor != 1 || len(m.pendingKeys) != 0 {
+		t.Errorf("Expected <leader>j to move down, cursor at %d", m.itemCursor)
+	}
+
+	// Unbound sequences are dropped without doing anything
+	press(",")
+	press("k")
+	if m.itemCursor != 1 || len(m.pendingKeys) != 0 {
+		t.Errorf("Expected an unbound sequence to be dropped, cursor at %d", m.itemCursor)
+	}
+}
+
+func TestCmdAdd_DateOnAdd(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "todo.txt")
+
+	m := NewModel(tmpFile, Options{DateOnAdd: true})
+	m, _ = m.cmdAdd("Task @Work")
+	m, _ = m.cmdAdd("2020-01-01 Dated task")
+
+	today := time.Now().Format("2006-01-02")
+	if m.todos[0].CreationDate != today || m.todos[0].String() != today+" Task @Work" {
+		t.Errorf("Expected today as creation date, got %q", m.todos[0].String())
+	}
+	if m.todos[1].CreationDate != "2020-01-01" {
+		t.Errorf("Expected the given creation date to stay, got %q", m.todos[1].String())
+	}
+}
+
+func TestArchiveBrowser_Restore(t *testing.T) {
+	dir := t.TempDir()
+	tmpFile := filepath.Join(dir, "todo.txt")
+	archiveFile := filepath.Join(dir, "todo_archive_2020_01.txt")
+	if err := os.WriteFile(tmpFile, []byte("Open task\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	archive := "x 2020-01-05 Plant tulips +garden\nx 2020-01-06 Call bank\nx 2020-01-07 Fix fence +garden\n"
+	if err := os.WriteFile(archiveFile, []byte(archive), 0644); err != nil {
+		t.Fatalf("Failed to create archive file: %v", err)
+	}
+
+	m := NewModel(tmpFile, Options{})
+	press := func(k string) {
+		model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		m = model.(Model)
+	}
+
+	m, _ = m.cmdArchive("browse +garden")
+	if m.mode != ModeArchive || len(m.groups) != 1 || len(m.groups[0].Todos) != 2 {
+		t.Fatalf("Expected the 2 garden tasks in the browser, got mode %v and groups %+v", m.mode, m.groups)
+	}
+	if !strings.Contains(m.View(), "todo_archive_2020_01.txt") {
+		t.Error("Expected the archive file as list header")
+	}
+
+	// Mark both tasks and restore them
+	press("m")
+	press("m")
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = model.(Model)
+	if got, _ := os.ReadFile(tmpFile); string(got) != "Open task\nPlant tulips +garden\nFix fence +garden\n" {
+		t.Errorf("File after restore = %q", got)
+	}
+	if got, _ := os.ReadFile(archiveFile); string(got) != "x 2020-01-06 Call bank\n" {
+		t.Errorf("Archive after restore = %q", got)
+	}
+	if m.mode != ModeArchive || len(m.groups) != 0 || m.status.text != "restored 2 tasks" {
+		t.Errorf("Expected an empty browser after restoring, got groups %+v and status %q", m.groups, m.status.text)
+	}
+
+	// Closing the browser shows todo.txt again, where the restore can be undone
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = model.(Model)
+	if m.mode != ModeNormal || len(m.positions()) != 3 {
+		t.Fatalf("Expected the 3 open tasks after closing the browser, got %d", len(m.positions()))
+	}
+	if _, err := m.undo(); err != nil {
+		t.Fatalf("undo() error = %v", err)
+	}
+	if got, _ := os.ReadFile(tmpFile); string(got) != "Open task\n" {
+		t.Errorf("File after undo = %q", got)
+	}
+	if got, _ := os.ReadFile(archiveFile); !strings.Contains(string(got), "x 2020-01-05 Plant tulips +garden") {
+		t.Errorf("Expected the task back in the archive after undo, got %q", got)
+	}
+}
+
+func TestArchiveBrowser_RestoreSaveFailureKeepsArchive(t *testing.T) {
+	dir := t.TempDir()
+	tmpFile := filepath.Join(dir, "todo.txt")
+	archiveFile := filepath.Join(dir, "todo_archive_2020_01.txt")
+	if err := os.WriteFile(tmpFile, []byte("Open task\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(archiveFile, []byte("x 2020-01-05 Plant tulips\n"), 0644); err != nil {
+		t.Fatalf("Failed to create archive file: %v", err)
+	}
+
+	m := NewModel(tmpFile, Options{})
+	m, _ = m.cmdArchive("browse")
+
+	// Another program changes todo.txt, so the save is refused
+	if err := os.WriteFile(tmpFile, []byte("Open task\nAdded elsewhere\n"), 0644); err != nil {
+		t.Fatalf("Failed to change test file: %v", err)
+	}
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = model.(Model)
+
+	if !m.status.isError || !strings.HasPrefix(m.status.text, "restore failed") {
+		t.Errorf("Expected the restore to fail, got %+v", m.status)
+	}
+	if got, _ := os.ReadFile(archiveFile); string(got) != "x 2020-01-05 Plant tulips\n" {
+		t.Errorf("Archive after failed restore = %q, want it unchanged", got)
+	}
+	if len(m.todos) != 1 || len(m.history.undo) != 0 || len(m.pendingJournal) != 0 || m.dirty {
+		t.Errorf("Expected a failed restore to change nothing, got %d todos, %d undo, %d journal entries, dirty %v",
+			len(m.todos), len(m.history.undo), len(m.pendingJournal), m.dirty)
+	}
+	if len(m.archived) != 1 || len(m.groups) != 1 {
+		t.Errorf("Expected the task to stay in the browser, got %d archived", len(m.archived))
+	}
+}
+
+func TestQuit_RefusesWithUnsavedChanges(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "todo.txt")
+	if err := os.WriteFile(tmpFile, []byte("Task one @Work\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	isQuit := func(cmd tea.Cmd) bool {
+		if cmd == nil {
+			return false
+		}
+		_, ok := cmd().(tea.QuitMsg)
+		return ok
+	}
+
+	m := NewModel(tmpFile, Options{})
+	if _, cmd := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}); !isQuit(cmd) {
+		t.Error("Expected q to quit without unsaved changes")
+	}
+
+	m.dirty = true
+	for _, msg := range []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("q")}, {Type: tea.KeyCtrlC}} {
+		model, cmd := m.handleKeyPress(msg)
+		if isQuit(cmd) {
+			t.Errorf("Expected %s not to quit with unsaved changes", msg)
+		}
+		if status := model.(Model).status; !status.isError || status.text != "unsaved changes, :w or :q!" {
+			t.Errorf("Expected an unsaved changes error for %s, got %+v", msg, status)
+		}
+	}
+
+	m.commandInput.SetValue("q")
+	if _, cmd := m.executeCommand(); isQuit(cmd) {
+		t.Error("Expected :q not to quit with unsaved changes")
+	}
+	m.commandInput.SetValue("q!")
+	if _, cmd := m.executeCommand(); !isQuit(cmd) {
+		t.Error("Expected :q! to quit")
 	}
 }
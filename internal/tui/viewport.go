@@ -0,0 +1,214 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// scrollMargin is the number of lines kept visible around the cursor when
+// scrolling, like vim's scrolloff
+const scrollMargin = 2
+
+// listLine is a rendered line of the todo lists
+type listLine struct {
+	text   string
+	isItem bool // True for todo lines, false for headers and spacing
+}
+
+// appendBlock appends the lines of a multi-line block to lines
+func appendBlock(lines []listLine, block string) []listLine {
+	for _, text := range strings.Split(block, "\n") {
+		lines = append(lines, listLine{text: text})
+	}
+	return lines
+}
+
+// listHeight returns the number of lines left for the lists between the
+// header and footer, or -1 if the terminal size is not known yet
+func (m Model) listHeight(footer string) int {
+	if m.height <= 0 {
+		return -1
+	}
+	header := strings.Count(m.renderHeader(), "\n")
+	return max(m.height-header-lipgloss.Height(footer), 1)
+}
+
+// scrollOffset returns the first line to show so that the cursor line stays
+// visible with some margin, keeping the previous offset where possible.
+// height is the number of lines shown, not counting the scroll indicators.
+func scrollOffset(offset, cursor, total, height int) int {
+	if height <= 0 || total <= height {
+		return 0
+	}
+	if cursor >= 0 {
+		margin := min(scrollMargin, (height-1)/2)
+		if cursor-margin < offset {
+			offset = cursor - margin
+		}
+		if cursor+margin >= offset+height {
+			offset = cursor + margin - height + 1
+		}
+	}
+	return min(max(offset, 0), total-height)
+}
+
+// visibleLines returns the number of list lines the viewport shows when the
+// lists don't fit, leaving room for the scroll indicators
+func visibleLines(height int) int {
+	return max(height-2, 1)
+}
+
+// layout holds what the scroll offset depends on: the cursor, the terminal
+// size, the lists and the state that changes the height of the footer
+type layout struct {
+	list, item       int
+	width, height    int
+	groupsVersion    int
+	mode             Mode
+	pendingKeys      int
+	confirmingDelete bool
+	waitingPriority  bool
+	conflict         bool
+	status           string
+	autocomplete     bool
+	command          string
+}
+
+// layout returns the current layout
+func (m Model) layout() layout {
+	return layout{
+		list:             m.listCursor,
+		item:             m.itemCursor,
+		width:            m.width,
+		height:           m.height,
+		groupsVersion:    m.groupsVersion,
+		mode:             m.mode,
+		pendingKeys:      len(m.pendingKeys),
+		confirmingDelete: m.confirmingDelete,
+		waitingPriority:  m.waitingPriority,
+		conflict:         m.conflict,
+		status:           m.status.text,
+		autocomplete:     m.showAutocomplete,
+		command:          m.commandInput.Value(),
+	}
+}
+
+// followCursor scrolls the viewport so that the cursor is visible. Rendering
+// the lists is costly, so nothing is done unless the layout changed.
+func (m *Model) followCursor() {
+	current := m.layout()
+	if current == m.offsetLayout {
+		return
+	}
+	m.offsetLayout = current
+
+	height := m.listHeight(m.renderFooter())
+	lines, cursor := m.renderLists()
+	if height < 0 || len(lines) <= height {
+		m.offset = 0
+		return
+	}
+	m.offset = scrollOffset(m.offset, cursor, len(lines), visibleLines(height))
+
+	// Don't hide the header of the first list when no task is above
+	if countItems(lines[:m.offset]) == 0 && cursor < visibleLines(height) {
+		m.offset = 0
+	}
+}
+
+// renderViewport renders the part of the lists that fits in height lines,
+// with indicators for the tasks scrolled out of view. A negative height
+// renders everything.
+func (m Model) renderViewport(lines []listLine, height int) string {
+	var s strings.Builder
+	if height < 0 || len(lines) <= height {
+		for _, line := range lines {
+			s.WriteString(m.fitWidth(line.text) + "\n")
+		}
+		return s.String()
+	}
+
+	visible := visibleLines(height)
+	offset := min(max(m.offset, 0), len(lines)-visible)
+	indicatorStyle := lipgloss.NewStyle().Foreground(m.styles.Theme.Muted).Italic(true)
+
+	// The indicator lines are always there, so the lists don't jump around
+	if above := countItems(lines[:offset]); above > 0 {
+		s.WriteString(indicatorStyle.Render(fmt.Sprintf("  ↑ %d more", above)))
+	}
+	s.WriteString("\n")
+	for _, line := range lines[offset : offset+visible] {
+		s.WriteString(m.fitWidth(line.text) + "\n")
+	}
+	if below := countItems(lines[offset+visible:]); below > 0 {
+		s.WriteString(indicatorStyle.Render(fmt.Sprintf("  ↓ %d more", below)))
+	}
+	s.WriteString("\n")
+
+	return s.String()
+}
+
+// fitWidth cuts text to the terminal width, lines wrapping around would push
+// the viewport off screen
+func (m Model) fitWidth(text string) string {
+	if m.width <= 0 {
+		return text
+	}
+	return lipgloss.NewStyle().MaxWidth(m.width).Render(text)
+}
+
+// countItems returns the number of todo lines in lines
+func countItems(lines []listLine) int {
+	count := 0
+	for _, line := range lines {
+		if line.isItem {
+			count++
+		}
+	}
+	return count
+}
+
+// positions returns the positions of all items in the order they are displayed
+func (m Model) positions() []position {
+	var positions []position
+	for listIdx, group := range m.groups {
+		for itemIdx := range group.Todos {
+			positions = append(positions, position{list: listIdx, item: itemIdx})
+		}
+	}
+	return positions
+}
+
+// moveCursor moves the cursor by delta items across lists
+func (m *Model) moveCursor(delta int) {
+	current := position{list: m.listCursor, item: m.itemCursor}
+	for idx, pos := range m.positions() {
+		if pos == current {
+			m.moveCursorTo(idx + delta)
+			return
+		}
+	}
+	m.moveCursorTo(0)
+}
+
+// moveCursorTo moves the cursor to the item at idx in display order, clamped
+// to the first and last item
+func (m *Model) moveCursorTo(idx int) {
+	positions := m.positions()
+	if len(positions) == 0 {
+		return
+	}
+	pos := positions[min(max(idx, 0), len(positions)-1)]
+	m.listCursor, m.itemCursor = pos.list, pos.item
+}
+
+// halfPage returns the number of items ctrl+d and ctrl+u move by
+func (m Model) halfPage() int {
+	height := m.listHeight(m.renderFooter())
+	if height < 0 {
+		height = len(m.positions())
+	}
+	return max(visibleLines(height)/2, 1)
+}
@@ -0,0 +1,211 @@
+package tui
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"tada/internal/todo"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// enterVisual starts selecting a range of items in the current list
+func (m Model) enterVisual() (tea.Model, tea.Cmd) {
+	if _, idx := m.getCurrentTodo(); idx == -1 {
+		return m, nil
+	}
+	m.mode = ModeVisual
+	m.visual = true
+	m.visualAnchor = m.itemCursor
+	return m, nil
+}
+
+// exitVisual drops the selection and returns to normal mode
+func (m *Model) exitVisual() {
+	m.mode = ModeNormal
+	m.visual = false
+	m.waitingPriority = false
+}
+
+// isSelected reports whether the item at itemIdx of list listIdx is selected
+func (m Model) isSelected(listIdx, itemIdx int) bool {
+	if m.browsing {
+		return m.archiveMarks[m.groups[listIdx].Todos[itemIdx].Index]
+	}
+	if !m.visual || listIdx != m.listCursor {
+		return false
+	}
+	start, end := m.visualAnchor, m.itemCursor
+	if start > end {
+		start, end = end, start
+	}
+	return itemIdx >= start && itemIdx <= end
+}
+
+// selection returns the indexes in m.todos that batch actions apply to:
+// the selected items in visual mode, otherwise the current item.
+// Indexes are in descending order, so removing them one by one keeps the
+// remaining indexes valid.
+func (m Model) selection() []int {
+	if !m.visual {
+		if _, idx := m.getCurrentTodo(); idx != -1 {
+			return []int{idx}
+		}
+		return nil
+	}
+
+	if m.listCursor >= len(m.groups) {
+		return nil
+	}
+	var indexes []int
+	for itemIdx, item := range m.groups[m.listCursor].Todos {
+		if m.isSelected(m.listCursor, itemIdx) && item.Index < len(m.todos) {
+			indexes = append(indexes, item.Index)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(indexes)))
+	return indexes
+}
+
+// handleVisualMode handles key presses in visual mode
+func (m Model) handleVisualMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Check if we're waiting for the priority to set
+	if m.waitingPriority {
+		m.waitingPriority = false
+		key := msg.String()
+		if key == "-" {
+			return m.prioritizeSelection("")
+		}
+		if len(key) == 1 && strings.ToUpper(key) >= "A" && strings.ToUpper(key) <= "Z" {
+			return m.prioritizeSelection(key)
+		}
+		// Anything else cancels
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc", "v":
+		m.exitVisual()
+	case "up", "k":
+		// The selection stays within the current list
+		if m.itemCursor > 0 {
+			m.itemCursor--
+		}
+	case "down", "j":
+		if m.listCursor < len(m.groups) && m.itemCursor < len(m.groups[m.listCursor].Todos)-1 {
+			m.itemCursor++
+		}
+	case "c":
+		return m.completeSelection()
+	case "d", "x":
+		return m.deleteSelection()
+	case "p":
+		m.waitingPriority = true
+	case "+":
+		return m.promptSelection("tag ")
+	case "-":
+		return m.promptSelection("untag ")
+	case ":":
+		return m.promptSelection("")
+	}
+
+	return m, nil
+}
+
+// promptSelection opens command mode for the selection, prefilled with value
+func (m Model) promptSelection(value string) (tea.Model, tea.Cmd) {
+	m.mode = ModeCommand
+	m.commandInput.Reset()
+	m.commandInput.SetValue(value)
+	m.commandInput.CursorEnd()
+	m.commandInput.Focus()
+	return m, textinput.Blink
+}
+
+// applySelection applies fn to every selected todo, records the change and
+// saves. Nothing changes if fn fails for any of them. The selection is
+// dropped afterwards.
+func (m Model) applySelection(label string, fn func(todos []todo.Item, idx int) ([]todo.Item, error)) (Model, tea.Cmd) {
+	indexes := m.selection()
+	m.exitVisual()
+	m.commandInput.Blur()
+	if len(indexes) == 0 {
+		return m, nil
+	}
+
+	before := m.snapshot()
+	for _, idx := range indexes {
+		todos, err := fn(m.todos, idx)
+		if err != nil {
+			m.restore(before)
+			cmd := m.setError(err)
+			return m, cmd
+		}
+		m.todos = todos
+	}
+
+	// Completing completed tasks and the like is a no-op
+	if slices.Equal(before, m.snapshot()) {
+		return m, nil
+	}
+	m.record(label, before, nil)
+
+	// Save to file
+	if err := m.save(); err != nil {
+		cmd := m.saveFailed(err)
+		return m, cmd
+	}
+
+	// Refresh groups
+	m.refreshGroups()
+
+	cmd := m.setStatus("%s %s", opVerbs[label], tasks(len(indexes)))
+	return m, cmd
+}
+
+// completeSelection marks the selected tasks as complete
+func (m Model) completeSelection() (Model, tea.Cmd) {
+	now := time.Now()
+	return m.applySelection(todo.OpComplete, func(todos []todo.Item, idx int) ([]todo.Item, error) {
+		// Recurring copies are appended, so the other indexes stay valid
+		return todo.Complete(todos, idx, now), nil
+	})
+}
+
+// deleteSelection deletes the selected tasks
+func (m Model) deleteSelection() (Model, tea.Cmd) {
+	return m.applySelection(todo.OpDelete, func(todos []todo.Item, idx int) ([]todo.Item, error) {
+		return todo.Remove(todos, idx), nil
+	})
+}
+
+// prioritizeSelection sets the priority of the selected tasks, "" clears it
+func (m Model) prioritizeSelection(priority string) (Model, tea.Cmd) {
+	return m.applySelection(todo.OpPriority, func(todos []todo.Item, idx int) ([]todo.Item, error) {
+		return todos, todo.SetPriority(todos, idx, priority)
+	})
+}
+
+// cmdTag adds @context, +project or key:value tokens to the selected tasks
+func (m Model) cmdTag(args string) (Model, tea.Cmd) {
+	tokens := strings.Fields(args)
+	if len(tokens) == 0 {
+		return m, nil
+	}
+	return m.applySelection(todo.OpEdit, func(todos []todo.Item, idx int) ([]todo.Item, error) {
+		return todos, todo.AddTokens(todos, idx, tokens)
+	})
+}
+
+// cmdUntag removes @context, +project or key tokens from the selected tasks
+func (m Model) cmdUntag(args string) (Model, tea.Cmd) {
+	tokens := strings.Fields(args)
+	if len(tokens) == 0 {
+		return m, nil
+	}
+	return m.applySelection(todo.OpEdit, func(todos []todo.Item, idx int) ([]todo.Item, error) {
+		return todos, todo.RemoveTokens(todos, idx, tokens)
+	})
+}
@@ -0,0 +1,156 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"tada/internal/todo"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fileCheckInterval is how often todo.txt is checked for external changes
+const fileCheckInterval = time.Second
+
+// errConflict is returned when todo.txt changed on disk while the in-memory
+// list has changes that were not saved yet
+var errConflict = errors.New("todo.txt was changed by another program")
+
+// errUnsaved is reported when quitting would lose changes that are not on disk
+var errUnsaved = errors.New("unsaved changes, :w or :q!")
+
+// fileCheckMsg triggers a check of todo.txt for external changes
+type fileCheckMsg struct{}
+
+// watchFile schedules the next check of todo.txt
+func watchFile() tea.Cmd {
+	return tea.Tick(fileCheckInterval, func(time.Time) tea.Msg {
+		return fileCheckMsg{}
+	})
+}
+
+// checkFile reloads todo.txt if another program changed it
+// If the in-memory list has unsaved changes too, a conflict is flagged
+// instead, which the user resolves with :reload or :write. Returns the
+// command clearing the message about the reload.
+func (m *Model) checkFile() tea.Cmd {
+	version, err := todo.StatFile(m.filename)
+	if err != nil || version.Equal(m.fileVersion) {
+		return nil
+	}
+
+	if m.dirty {
+		m.conflict = true
+		return nil
+	}
+
+	// Don't pull the list from under an edit, a selection or a pending delete,
+	// try again later
+	if m.mode == ModeInsert || m.visual || m.confirmingDelete {
+		return nil
+	}
+
+	// Keep showing the current list if the file can't be read
+	if err := m.reload(); err != nil {
+		return nil
+	}
+	return m.setStatus("%s changed on disk, reloaded", filepath.Base(m.filename))
+}
+
+// reload replaces the in-memory todos with the content of todo.txt
+func (m *Model) reload() error {
+	var todos []todo.Item
+	var version todo.FileVersion
+	err := todo.WithLock(m.filename, func() error {
+		var err error
+		if version, err = todo.StatFile(m.filename); err != nil {
+			return err
+		}
+		todos, err = todo.LoadFromFile(m.filename)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	m.todos = todos
+	m.fileVersion = version
+	m.dirty = false
+	m.conflict = false
+	m.history = history{} // Snapshots don't account for the external changes
+	m.pendingJournal = nil
+	m.refreshGroups()
+	return nil
+}
+
+// save writes the todos to todo.txt
+// If another program changed the file since it was read, nothing is written
+// and a conflict is flagged, so external edits are never clobbered. The
+// groups always reflect the in-memory todos.
+func (m *Model) save() error {
+	return m.saveMoved(nil, nil)
+}
+
+// saveMoved is save for changes that moved todos between todo.txt and the
+// archive files. The archive files are updated while holding the lock as
+// well: archived todos are appended before todo.txt is written and restored
+// ones taken out after it. If appending or writing fails, the archive files
+// are left as they were.
+func (m *Model) saveMoved(archived, restored []todo.Item) error {
+	m.dirty = true
+	if m.conflict {
+		m.refreshGroups()
+		return errConflict
+	}
+
+	err := todo.WithLock(m.filename, func() error {
+		if version, err := todo.StatFile(m.filename); err == nil && !version.Equal(m.fileVersion) {
+			m.conflict = true
+			return errConflict
+		}
+		if err := m.archive.Append(archived); err != nil {
+			return err
+		}
+		err := m.writeLocked()
+		if err != nil && !errors.Is(err, todo.ErrJournal) {
+			_ = m.archive.Remove(archived) // Best effort rollback, the save error matters more
+			return err
+		}
+		if removeErr := m.archive.Remove(restored); removeErr != nil {
+			return fmt.Errorf("%w: %w", errStillArchived, removeErr)
+		}
+		return err
+	})
+	if err != nil {
+		m.refreshGroups()
+	}
+	return err
+}
+
+// write writes the todos to todo.txt, overwriting any external changes
+func (m *Model) write() error {
+	err := todo.WithLock(m.filename, m.writeLocked)
+	if err != nil {
+		m.refreshGroups()
+	}
+	return err
+}
+
+// writeLocked writes the todos to todo.txt and the pending journal entries
+// to the journal, the caller must hold the lock
+func (m *Model) writeLocked() error {
+	if err := todo.SaveToFile(m.filename, m.todos); err != nil {
+		return err
+	}
+
+	m.fileVersion, _ = todo.StatFile(m.filename) // Zero version forces a reload check later
+	m.dirty = false
+	m.conflict = false
+
+	entries := m.pendingJournal
+	m.pendingJournal = nil
+	_, err := todo.AppendJournal(m.filename, entries...)
+	return err
+}